@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"srv.exe.dev/db"
+	"srv.exe.dev/srv"
+)
+
+// addUser implements "srv adduser NAME": it asks for a password and
+// creates an admin user in the database.
+func addUser(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: srv adduser NAME")
+	}
+	password, err := readPassword()
+	if err != nil {
+		return err
+	}
+
+	database, err := db.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("open db: %w", err)
+	}
+	defer database.Close()
+	if err := db.RunMigrations(database); err != nil {
+		return fmt.Errorf("run migrations: %w", err)
+	}
+	if err := srv.AddAdminUser(context.Background(), database, args[0], password); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "created admin user %q\n", args[0])
+	return nil
+}
+
+// readPassword prompts for a password twice on a terminal. Otherwise it
+// reads the first line of stdin, so the command can be scripted.
+func readPassword() (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("read password: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	first, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprint(os.Stderr, "Repeat password: ")
+	second, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	if string(first) != string(second) {
+		return "", errors.New("passwords do not match")
+	}
+	return string(first), nil
+}
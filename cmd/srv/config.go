@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// config is the optional JSON config file. Flags and environment variables
+// override its values.
+type config struct {
+	BaseURL string `json:"base_url"`
+
+	// TrustedProxies are the CIDRs the exe.dev proxy connects from, and
+	// AdminEmails maps the exe.dev emails allowed into the admin to a
+	// role. Both must be set for exe.dev logins to work.
+	TrustedProxies []string          `json:"trusted_proxies"`
+	AdminEmails    map[string]string `json:"admin_emails"`
+}
+
+// loadConfig reads the config file at path. A missing file is not an error
+// unless required is set.
+func loadConfig(path string, required bool) (config, error) {
+	var cfg config
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !required {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return cfg, nil
+}
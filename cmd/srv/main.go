@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
@@ -8,23 +9,65 @@ import (
 	"srv.exe.dev/srv"
 )
 
-var flagListenAddr = flag.String("listen", ":8000", "address to listen on")
+const (
+	defaultConfigPath = "config.json"
+	dbPath            = "db.sqlite3"
+)
+
+var (
+	flagListenAddr = flag.String("listen", ":8000", "address to listen on")
+	flagClickFlush = flag.Duration("click-flush", srv.DefaultClickFlushInterval, "how often queued clicks are written to the database")
+	flagConfig     = flag.String("config", defaultConfigPath, "path to the JSON config file")
+	flagBaseURL    = flag.String("base-url", "", "canonical site URL, e.g. https://kohlschwarz.at:8000 (overrides $BASE_URL and the config file)")
+	flagDev        = flag.Bool("dev", false, "local development: allow plain-HTTP session cookies and create a default admin user if there is none")
+)
 
 func main() {
 	if err := run(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 }
 
 func run() error {
 	flag.Parse()
+	if flag.Arg(0) == "adduser" {
+		return addUser(flag.Args()[1:])
+	}
+
+	cfg, err := loadConfig(*flagConfig, *flagConfig != defaultConfigPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	baseURL := cfg.BaseURL
+	if env := os.Getenv("BASE_URL"); env != "" {
+		baseURL = env
+	}
+	if *flagBaseURL != "" {
+		baseURL = *flagBaseURL
+	}
+	if baseURL, err = srv.ParseBaseURL(baseURL); err != nil {
+		return err
+	}
+	exeDev, err := srv.NewExeDevAuth(cfg.TrustedProxies, cfg.AdminEmails)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+
 	hostname, err := os.Hostname()
 	if err != nil {
 		hostname = "unknown"
 	}
-	server, err := srv.New("db.sqlite3", hostname)
+	server, err := srv.New(dbPath, hostname)
 	if err != nil {
 		return fmt.Errorf("create server: %w", err)
 	}
+	server.ClickFlushInterval = *flagClickFlush
+	server.BaseURL = baseURL
+	server.Dev = *flagDev
+	server.ExeDev = exeDev
+	if err := server.EnsureAdmin(context.Background()); err != nil {
+		return err
+	}
 	return server.Serve(*flagListenAddr)
 }
@@ -20,7 +20,10 @@ var migrationFS embed.FS
 
 // Open opens an sqlite database and prepares pragmas suitable for a small web app.
 func Open(path string) (*sql.DB, error) {
-	db, err := sql.Open("sqlite", path)
+	// Write time.Time values in a format SQLite's date functions understand.
+	// The busy timeout is a per-connection setting, so it goes in the DSN
+	// to reach every connection in the pool, not just the first.
+	db, err := sql.Open("sqlite", path+"?_time_format=sqlite&_pragma=busy_timeout(1000)")
 	if err != nil {
 		return nil, err
 	}
@@ -33,10 +36,6 @@ func Open(path string) (*sql.DB, error) {
 		_ = db.Close()
 		return nil, fmt.Errorf("set WAL: %w", err)
 	}
-	if _, err := db.Exec("PRAGMA busy_timeout=1000;"); err != nil {
-		_ = db.Close()
-		return nil, fmt.Errorf("set busy_timeout: %w", err)
-	}
 	return db, nil
 }
 
@@ -9,31 +9,61 @@ import (
 	"context"
 )
 
+const addClickCount = `-- name: AddClickCount :exec
+UPDATE apps SET click_count = click_count + CAST(?1 AS INTEGER) WHERE id = ?2
+`
+
+type AddClickCountParams struct {
+	Clicks int64 `json:"clicks"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddClickCount(ctx context.Context, arg AddClickCountParams) error {
+	_, err := q.db.ExecContext(ctx, addClickCount, arg.Clicks, arg.ID)
+	return err
+}
+
 const createApp = `-- name: CreateApp :one
-INSERT INTO apps (url, title, description, shelley_command, thumbnail, sort_order, prompt, created_at, updated_at)
-VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-RETURNING id, url, title, description, shelley_command, thumbnail, sort_order, created_at, updated_at, prompt, click_count
+INSERT INTO apps (url, title, slug, description, shelley_command, thumbnail, sort_order, prompt, utm_source, utm_medium, utm_campaign, pinned, authors, version, doi, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+RETURNING id, url, title, description, shelley_command, thumbnail, sort_order, created_at, updated_at, prompt, click_count, utm_source, utm_medium, utm_campaign, pinned, slug, authors, version, doi
 `
 
 type CreateAppParams struct {
 	Url            string  `json:"url"`
 	Title          string  `json:"title"`
+	Slug           string  `json:"slug"`
 	Description    string  `json:"description"`
 	ShelleyCommand *string `json:"shelley_command"`
 	Thumbnail      *string `json:"thumbnail"`
 	SortOrder      *int64  `json:"sort_order"`
 	Prompt         *string `json:"prompt"`
+	UtmSource      *string `json:"utm_source"`
+	UtmMedium      *string `json:"utm_medium"`
+	UtmCampaign    *string `json:"utm_campaign"`
+	Pinned         bool    `json:"pinned"`
+	Authors        string  `json:"authors"`
+	Version        string  `json:"version"`
+	Doi            string  `json:"doi"`
 }
 
 func (q *Queries) CreateApp(ctx context.Context, arg CreateAppParams) (App, error) {
 	row := q.db.QueryRowContext(ctx, createApp,
 		arg.Url,
 		arg.Title,
+		arg.Slug,
 		arg.Description,
 		arg.ShelleyCommand,
 		arg.Thumbnail,
 		arg.SortOrder,
 		arg.Prompt,
+		arg.UtmSource,
+		arg.UtmMedium,
+		arg.UtmCampaign,
+		arg.Pinned,
+		arg.Authors,
+		arg.Version,
+		arg.Doi,
 	)
 	var i App
 	err := row.Scan(
@@ -48,6 +78,14 @@ func (q *Queries) CreateApp(ctx context.Context, arg CreateAppParams) (App, erro
 		&i.UpdatedAt,
 		&i.Prompt,
 		&i.ClickCount,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.Pinned,
+		&i.Slug,
+		&i.Authors,
+		&i.Version,
+		&i.Doi,
 	)
 	return i, err
 }
@@ -62,7 +100,7 @@ func (q *Queries) DeleteApp(ctx context.Context, id int64) error {
 }
 
 const getApp = `-- name: GetApp :one
-SELECT id, url, title, description, shelley_command, thumbnail, sort_order, created_at, updated_at, prompt, click_count FROM apps WHERE id = ?
+SELECT id, url, title, description, shelley_command, thumbnail, sort_order, created_at, updated_at, prompt, click_count, utm_source, utm_medium, utm_campaign, pinned, slug, authors, version, doi FROM apps WHERE id = ?
 `
 
 func (q *Queries) GetApp(ctx context.Context, id int64) (App, error) {
@@ -80,21 +118,51 @@ func (q *Queries) GetApp(ctx context.Context, id int64) (App, error) {
 		&i.UpdatedAt,
 		&i.Prompt,
 		&i.ClickCount,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.Pinned,
+		&i.Slug,
+		&i.Authors,
+		&i.Version,
+		&i.Doi,
 	)
 	return i, err
 }
 
-const incrementClickCount = `-- name: IncrementClickCount :exec
-UPDATE apps SET click_count = click_count + 1 WHERE id = ?
+const getAppBySlug = `-- name: GetAppBySlug :one
+SELECT id, url, title, description, shelley_command, thumbnail, sort_order, created_at, updated_at, prompt, click_count, utm_source, utm_medium, utm_campaign, pinned, slug, authors, version, doi FROM apps WHERE slug = ?
 `
 
-func (q *Queries) IncrementClickCount(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, incrementClickCount, id)
-	return err
+func (q *Queries) GetAppBySlug(ctx context.Context, slug string) (App, error) {
+	row := q.db.QueryRowContext(ctx, getAppBySlug, slug)
+	var i App
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		&i.Title,
+		&i.Description,
+		&i.ShelleyCommand,
+		&i.Thumbnail,
+		&i.SortOrder,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Prompt,
+		&i.ClickCount,
+		&i.UtmSource,
+		&i.UtmMedium,
+		&i.UtmCampaign,
+		&i.Pinned,
+		&i.Slug,
+		&i.Authors,
+		&i.Version,
+		&i.Doi,
+	)
+	return i, err
 }
 
 const listApps = `-- name: ListApps :many
-SELECT id, url, title, description, shelley_command, thumbnail, sort_order, created_at, updated_at, prompt, click_count FROM apps ORDER BY click_count DESC, sort_order ASC, id ASC
+SELECT id, url, title, description, shelley_command, thumbnail, sort_order, created_at, updated_at, prompt, click_count, utm_source, utm_medium, utm_campaign, pinned, slug, authors, version, doi FROM apps ORDER BY sort_order ASC, id ASC
 `
 
 func (q *Queries) ListApps(ctx context.Context) ([]App, error) {
@@ -118,6 +186,108 @@ func (q *Queries) ListApps(ctx context.Context) ([]App, error) {
 			&i.UpdatedAt,
 			&i.Prompt,
 			&i.ClickCount,
+			&i.UtmSource,
+			&i.UtmMedium,
+			&i.UtmCampaign,
+			&i.Pinned,
+			&i.Slug,
+			&i.Authors,
+			&i.Version,
+			&i.Doi,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAppsWithoutSlug = `-- name: ListAppsWithoutSlug :many
+SELECT id, url, title, description, shelley_command, thumbnail, sort_order, created_at, updated_at, prompt, click_count, utm_source, utm_medium, utm_campaign, pinned, slug, authors, version, doi FROM apps WHERE slug = '' ORDER BY id
+`
+
+func (q *Queries) ListAppsWithoutSlug(ctx context.Context) ([]App, error) {
+	rows, err := q.db.QueryContext(ctx, listAppsWithoutSlug)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []App{}
+	for rows.Next() {
+		var i App
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.Title,
+			&i.Description,
+			&i.ShelleyCommand,
+			&i.Thumbnail,
+			&i.SortOrder,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Prompt,
+			&i.ClickCount,
+			&i.UtmSource,
+			&i.UtmMedium,
+			&i.UtmCampaign,
+			&i.Pinned,
+			&i.Slug,
+			&i.Authors,
+			&i.Version,
+			&i.Doi,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNewestApps = `-- name: ListNewestApps :many
+SELECT id, url, title, description, shelley_command, thumbnail, sort_order, created_at, updated_at, prompt, click_count, utm_source, utm_medium, utm_campaign, pinned, slug, authors, version, doi FROM apps ORDER BY created_at DESC, id DESC LIMIT ?
+`
+
+func (q *Queries) ListNewestApps(ctx context.Context, limit int64) ([]App, error) {
+	rows, err := q.db.QueryContext(ctx, listNewestApps, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []App{}
+	for rows.Next() {
+		var i App
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.Title,
+			&i.Description,
+			&i.ShelleyCommand,
+			&i.Thumbnail,
+			&i.SortOrder,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Prompt,
+			&i.ClickCount,
+			&i.UtmSource,
+			&i.UtmMedium,
+			&i.UtmCampaign,
+			&i.Pinned,
+			&i.Slug,
+			&i.Authors,
+			&i.Version,
+			&i.Doi,
 		); err != nil {
 			return nil, err
 		}
@@ -132,15 +302,37 @@ func (q *Queries) ListApps(ctx context.Context) ([]App, error) {
 	return items, nil
 }
 
+const setAppSlug = `-- name: SetAppSlug :exec
+UPDATE apps SET slug = ? WHERE id = ?
+`
+
+type SetAppSlugParams struct {
+	Slug string `json:"slug"`
+	ID   int64  `json:"id"`
+}
+
+func (q *Queries) SetAppSlug(ctx context.Context, arg SetAppSlugParams) error {
+	_, err := q.db.ExecContext(ctx, setAppSlug, arg.Slug, arg.ID)
+	return err
+}
+
 const updateApp = `-- name: UpdateApp :exec
 UPDATE apps SET
     url = ?,
     title = ?,
+    slug = ?,
     description = ?,
     shelley_command = ?,
     thumbnail = ?,
     sort_order = ?,
     prompt = ?,
+    utm_source = ?,
+    utm_medium = ?,
+    utm_campaign = ?,
+    pinned = ?,
+    authors = ?,
+    version = ?,
+    doi = ?,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
 `
@@ -148,11 +340,19 @@ WHERE id = ?
 type UpdateAppParams struct {
 	Url            string  `json:"url"`
 	Title          string  `json:"title"`
+	Slug           string  `json:"slug"`
 	Description    string  `json:"description"`
 	ShelleyCommand *string `json:"shelley_command"`
 	Thumbnail      *string `json:"thumbnail"`
 	SortOrder      *int64  `json:"sort_order"`
 	Prompt         *string `json:"prompt"`
+	UtmSource      *string `json:"utm_source"`
+	UtmMedium      *string `json:"utm_medium"`
+	UtmCampaign    *string `json:"utm_campaign"`
+	Pinned         bool    `json:"pinned"`
+	Authors        string  `json:"authors"`
+	Version        string  `json:"version"`
+	Doi            string  `json:"doi"`
 	ID             int64   `json:"id"`
 }
 
@@ -160,11 +360,19 @@ func (q *Queries) UpdateApp(ctx context.Context, arg UpdateAppParams) error {
 	_, err := q.db.ExecContext(ctx, updateApp,
 		arg.Url,
 		arg.Title,
+		arg.Slug,
 		arg.Description,
 		arg.ShelleyCommand,
 		arg.Thumbnail,
 		arg.SortOrder,
 		arg.Prompt,
+		arg.UtmSource,
+		arg.UtmMedium,
+		arg.UtmCampaign,
+		arg.Pinned,
+		arg.Authors,
+		arg.Version,
+		arg.Doi,
 		arg.ID,
 	)
 	return err
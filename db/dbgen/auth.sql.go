@@ -0,0 +1,366 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: auth.sql
+
+package dbgen
+
+import (
+	"context"
+	"time"
+)
+
+const countAdminOwners = `-- name: CountAdminOwners :one
+SELECT COUNT(*) FROM admin_users WHERE role = 'owner'
+`
+
+func (q *Queries) CountAdminOwners(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAdminOwners)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countAdminUsers = `-- name: CountAdminUsers :one
+SELECT COUNT(*) FROM admin_users
+`
+
+func (q *Queries) CountAdminUsers(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAdminUsers)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAdminUser = `-- name: CreateAdminUser :one
+INSERT INTO admin_users (username, password_hash, role)
+VALUES (?, ?, ?)
+RETURNING id, username, password_hash, created_at, last_login_at, role
+`
+
+type CreateAdminUserParams struct {
+	Username     string `json:"username"`
+	PasswordHash string `json:"password_hash"`
+	Role         string `json:"role"`
+}
+
+func (q *Queries) CreateAdminUser(ctx context.Context, arg CreateAdminUserParams) (AdminUser, error) {
+	row := q.db.QueryRowContext(ctx, createAdminUser, arg.Username, arg.PasswordHash, arg.Role)
+	var i AdminUser
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.LastLoginAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const createInvite = `-- name: CreateInvite :exec
+INSERT INTO invites (id, role, created_by, created_at, expires_at)
+VALUES (?, ?, ?, ?, ?)
+`
+
+type CreateInviteParams struct {
+	ID        string    `json:"id"`
+	Role      string    `json:"role"`
+	CreatedBy *int64    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateInvite(ctx context.Context, arg CreateInviteParams) error {
+	_, err := q.db.ExecContext(ctx, createInvite,
+		arg.ID,
+		arg.Role,
+		arg.CreatedBy,
+		arg.CreatedAt,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const createSession = `-- name: CreateSession :exec
+INSERT INTO sessions (id, user_id, created_at, expires_at)
+VALUES (?, ?, ?, ?)
+`
+
+type CreateSessionParams struct {
+	ID        string    `json:"id"`
+	UserID    int64     `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) error {
+	_, err := q.db.ExecContext(ctx, createSession,
+		arg.ID,
+		arg.UserID,
+		arg.CreatedAt,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const deleteAdminUser = `-- name: DeleteAdminUser :exec
+DELETE FROM admin_users WHERE id = ?
+`
+
+func (q *Queries) DeleteAdminUser(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteAdminUser, id)
+	return err
+}
+
+const deleteExpiredSessions = `-- name: DeleteExpiredSessions :exec
+DELETE FROM sessions WHERE expires_at <= ?
+`
+
+func (q *Queries) DeleteExpiredSessions(ctx context.Context, expiresAt time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredSessions, expiresAt)
+	return err
+}
+
+const deleteInvite = `-- name: DeleteInvite :exec
+DELETE FROM invites WHERE id = ?
+`
+
+func (q *Queries) DeleteInvite(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteInvite, id)
+	return err
+}
+
+const deleteSession = `-- name: DeleteSession :exec
+DELETE FROM sessions WHERE id = ?
+`
+
+func (q *Queries) DeleteSession(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteSession, id)
+	return err
+}
+
+const getAdminUser = `-- name: GetAdminUser :one
+SELECT id, username, password_hash, created_at, last_login_at, role FROM admin_users WHERE id = ?
+`
+
+func (q *Queries) GetAdminUser(ctx context.Context, id int64) (AdminUser, error) {
+	row := q.db.QueryRowContext(ctx, getAdminUser, id)
+	var i AdminUser
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.LastLoginAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const getAdminUserByName = `-- name: GetAdminUserByName :one
+SELECT id, username, password_hash, created_at, last_login_at, role FROM admin_users WHERE username = ?
+`
+
+func (q *Queries) GetAdminUserByName(ctx context.Context, username string) (AdminUser, error) {
+	row := q.db.QueryRowContext(ctx, getAdminUserByName, username)
+	var i AdminUser
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.LastLoginAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const getOpenInvite = `-- name: GetOpenInvite :one
+SELECT id, role, created_by, created_at, expires_at, used_at, used_by FROM invites
+WHERE id = ? AND used_at IS NULL AND expires_at > ?2
+`
+
+type GetOpenInviteParams struct {
+	ID  string    `json:"id"`
+	Now time.Time `json:"now"`
+}
+
+func (q *Queries) GetOpenInvite(ctx context.Context, arg GetOpenInviteParams) (Invite, error) {
+	row := q.db.QueryRowContext(ctx, getOpenInvite, arg.ID, arg.Now)
+	var i Invite
+	err := row.Scan(
+		&i.ID,
+		&i.Role,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.UsedBy,
+	)
+	return i, err
+}
+
+const getSessionUser = `-- name: GetSessionUser :one
+SELECT admin_users.id, admin_users.username, admin_users.password_hash, admin_users.created_at, admin_users.last_login_at, admin_users.role
+FROM sessions
+JOIN admin_users ON admin_users.id = sessions.user_id
+WHERE sessions.id = ? AND sessions.expires_at > ?2
+`
+
+type GetSessionUserParams struct {
+	ID  string    `json:"id"`
+	Now time.Time `json:"now"`
+}
+
+func (q *Queries) GetSessionUser(ctx context.Context, arg GetSessionUserParams) (AdminUser, error) {
+	row := q.db.QueryRowContext(ctx, getSessionUser, arg.ID, arg.Now)
+	var i AdminUser
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.LastLoginAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const listAdminUsers = `-- name: ListAdminUsers :many
+SELECT id, username, password_hash, created_at, last_login_at, role FROM admin_users ORDER BY username
+`
+
+func (q *Queries) ListAdminUsers(ctx context.Context) ([]AdminUser, error) {
+	rows, err := q.db.QueryContext(ctx, listAdminUsers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AdminUser{}
+	for rows.Next() {
+		var i AdminUser
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.PasswordHash,
+			&i.CreatedAt,
+			&i.LastLoginAt,
+			&i.Role,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOpenInvites = `-- name: ListOpenInvites :many
+SELECT invites.id, invites.role, invites.created_by, invites.created_at, invites.expires_at, invites.used_at, invites.used_by, admin_users.username AS created_by_name
+FROM invites
+LEFT JOIN admin_users ON admin_users.id = invites.created_by
+WHERE invites.used_at IS NULL AND invites.expires_at > ?1
+ORDER BY invites.created_at DESC
+`
+
+type ListOpenInvitesRow struct {
+	ID            string     `json:"id"`
+	Role          string     `json:"role"`
+	CreatedBy     *int64     `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	UsedAt        *time.Time `json:"used_at"`
+	UsedBy        *int64     `json:"used_by"`
+	CreatedByName *string    `json:"created_by_name"`
+}
+
+func (q *Queries) ListOpenInvites(ctx context.Context, now time.Time) ([]ListOpenInvitesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOpenInvites, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListOpenInvitesRow{}
+	for rows.Next() {
+		var i ListOpenInvitesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Role,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.ExpiresAt,
+			&i.UsedAt,
+			&i.UsedBy,
+			&i.CreatedByName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setAdminLastLogin = `-- name: SetAdminLastLogin :exec
+UPDATE admin_users SET last_login_at = ? WHERE id = ?
+`
+
+type SetAdminLastLoginParams struct {
+	LastLoginAt *time.Time `json:"last_login_at"`
+	ID          int64      `json:"id"`
+}
+
+func (q *Queries) SetAdminLastLogin(ctx context.Context, arg SetAdminLastLoginParams) error {
+	_, err := q.db.ExecContext(ctx, setAdminLastLogin, arg.LastLoginAt, arg.ID)
+	return err
+}
+
+const setAdminUserRole = `-- name: SetAdminUserRole :exec
+UPDATE admin_users SET role = ? WHERE id = ?
+`
+
+type SetAdminUserRoleParams struct {
+	Role string `json:"role"`
+	ID   int64  `json:"id"`
+}
+
+func (q *Queries) SetAdminUserRole(ctx context.Context, arg SetAdminUserRoleParams) error {
+	_, err := q.db.ExecContext(ctx, setAdminUserRole, arg.Role, arg.ID)
+	return err
+}
+
+const useInvite = `-- name: UseInvite :execrows
+UPDATE invites SET used_at = ?1, used_by = ?2
+WHERE id = ?3 AND used_at IS NULL AND expires_at > ?4
+`
+
+type UseInviteParams struct {
+	UsedAt *time.Time `json:"used_at"`
+	UsedBy *int64     `json:"used_by"`
+	ID     string     `json:"id"`
+	Now    time.Time  `json:"now"`
+}
+
+func (q *Queries) UseInvite(ctx context.Context, arg UseInviteParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, useInvite,
+		arg.UsedAt,
+		arg.UsedBy,
+		arg.ID,
+		arg.Now,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
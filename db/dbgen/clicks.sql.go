@@ -0,0 +1,232 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: clicks.sql
+
+package dbgen
+
+import (
+	"context"
+)
+
+const addClickRejections = `-- name: AddClickRejections :exec
+INSERT INTO click_rejections (day, reason, count)
+VALUES (date('now'), ?1, ?2)
+ON CONFLICT (day, reason) DO UPDATE SET count = click_rejections.count + excluded.count
+`
+
+type AddClickRejectionsParams struct {
+	Reason string `json:"reason"`
+	Count  int64  `json:"count"`
+}
+
+func (q *Queries) AddClickRejections(ctx context.Context, arg AddClickRejectionsParams) error {
+	_, err := q.db.ExecContext(ctx, addClickRejections, arg.Reason, arg.Count)
+	return err
+}
+
+const countClickRejection = `-- name: CountClickRejection :exec
+INSERT INTO click_rejections (day, reason, count)
+VALUES (date('now'), ?, 1)
+ON CONFLICT (day, reason) DO UPDATE SET count = click_rejections.count + 1
+`
+
+func (q *Queries) CountClickRejection(ctx context.Context, reason string) error {
+	_, err := q.db.ExecContext(ctx, countClickRejection, reason)
+	return err
+}
+
+const getClickRollupState = `-- name: GetClickRollupState :one
+SELECT last_event_id FROM click_rollup_state WHERE id = 1
+`
+
+func (q *Queries) GetClickRollupState(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getClickRollupState)
+	var last_event_id int64
+	err := row.Scan(&last_event_id)
+	return last_event_id, err
+}
+
+const insertClickEvent = `-- name: InsertClickEvent :exec
+INSERT INTO click_events (app_id, clicked_at, referrer_class, referrer_host, device_class)
+SELECT apps.id, CAST(?1 AS TEXT), CAST(?2 AS TEXT), CAST(?3 AS TEXT), CAST(?4 AS TEXT)
+FROM apps
+WHERE apps.id = ?5
+`
+
+type InsertClickEventParams struct {
+	ClickedAt     string `json:"clicked_at"`
+	ReferrerClass string `json:"referrer_class"`
+	ReferrerHost  string `json:"referrer_host"`
+	DeviceClass   string `json:"device_class"`
+	AppID         int64  `json:"app_id"`
+}
+
+// Selecting from apps skips clicks on apps deleted since they were queued.
+func (q *Queries) InsertClickEvent(ctx context.Context, arg InsertClickEventParams) error {
+	_, err := q.db.ExecContext(ctx, insertClickEvent,
+		arg.ClickedAt,
+		arg.ReferrerClass,
+		arg.ReferrerHost,
+		arg.DeviceClass,
+		arg.AppID,
+	)
+	return err
+}
+
+const listClickScores = `-- name: ListClickScores :many
+SELECT
+    app_id,
+    CAST(SUM(clicks * exp(-(julianday('now') - julianday(hour)) * 24 * CAST(?1 AS REAL))) AS REAL) AS score
+FROM (
+    SELECT app_id, hour, clicks FROM click_hourly
+    UNION ALL
+    SELECT app_id, strftime('%Y-%m-%d %H:00:00', clicked_at) AS hour, 1 AS clicks FROM click_events
+    WHERE id > (SELECT last_event_id FROM click_rollup_state WHERE id = 1)
+) AS c
+WHERE hour >= datetime('now', '-90 days')
+GROUP BY app_id
+`
+
+type ListClickScoresRow struct {
+	AppID int64   `json:"app_id"`
+	Score float64 `json:"score"`
+}
+
+// Time-decayed popularity: every click counts exp(-decay * age in hours),
+// so a click loses half its weight each half-life. Clicks older than 90
+// days are ignored. That includes the legacy totals booked on 1970-01-01
+// in click_daily: they have no real date, and popularity is about recent
+// interest, so they only show up in the all-time click counts.
+func (q *Queries) ListClickScores(ctx context.Context, decayPerHour float64) ([]ListClickScoresRow, error) {
+	rows, err := q.db.QueryContext(ctx, listClickScores, decayPerHour)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListClickScoresRow{}
+	for rows.Next() {
+		var i ListClickScoresRow
+		if err := rows.Scan(&i.AppID, &i.Score); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listClickStats = `-- name: ListClickStats :many
+SELECT
+    app_id,
+    CAST(COALESCE(SUM(CASE WHEN day >= date('now', '-6 days') THEN clicks ELSE 0 END), 0) AS INTEGER) AS clicks_7d,
+    CAST(COALESCE(SUM(CASE WHEN day >= date('now', '-29 days') THEN clicks ELSE 0 END), 0) AS INTEGER) AS clicks_30d,
+    CAST(COALESCE(SUM(clicks), 0) AS INTEGER) AS clicks_total
+FROM (
+    SELECT app_id, day, clicks FROM click_daily
+    UNION ALL
+    SELECT app_id, date(clicked_at) AS day, 1 AS clicks FROM click_events
+    WHERE id > (SELECT last_event_id FROM click_rollup_state WHERE id = 1)
+) AS c
+GROUP BY app_id
+`
+
+type ListClickStatsRow struct {
+	AppID       int64 `json:"app_id"`
+	Clicks7d    int64 `json:"clicks_7d"`
+	Clicks30d   int64 `json:"clicks_30d"`
+	ClicksTotal int64 `json:"clicks_total"`
+}
+
+// Clicks per app over the last 7 and 30 days and in total. Events that the
+// background rollup has not reached yet are counted straight from the log.
+func (q *Queries) ListClickStats(ctx context.Context) ([]ListClickStatsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listClickStats)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListClickStatsRow{}
+	for rows.Next() {
+		var i ListClickStatsRow
+		if err := rows.Scan(
+			&i.AppID,
+			&i.Clicks7d,
+			&i.Clicks30d,
+			&i.ClicksTotal,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const maxClickEventID = `-- name: MaxClickEventID :one
+SELECT CAST(COALESCE(MAX(id), 0) AS INTEGER) AS max_id FROM click_events
+`
+
+func (q *Queries) MaxClickEventID(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, maxClickEventID)
+	var max_id int64
+	err := row.Scan(&max_id)
+	return max_id, err
+}
+
+const rollupClicksDaily = `-- name: RollupClicksDaily :exec
+INSERT INTO click_daily (app_id, day, clicks)
+SELECT app_id, date(clicked_at), COUNT(*)
+FROM click_events
+WHERE id > ?1 AND id <= ?2
+GROUP BY 1, 2
+ON CONFLICT (app_id, day) DO UPDATE SET clicks = click_daily.clicks + excluded.clicks
+`
+
+type RollupClicksDailyParams struct {
+	AfterID int64 `json:"after_id"`
+	UpToID  int64 `json:"up_to_id"`
+}
+
+func (q *Queries) RollupClicksDaily(ctx context.Context, arg RollupClicksDailyParams) error {
+	_, err := q.db.ExecContext(ctx, rollupClicksDaily, arg.AfterID, arg.UpToID)
+	return err
+}
+
+const rollupClicksHourly = `-- name: RollupClicksHourly :exec
+INSERT INTO click_hourly (app_id, hour, clicks)
+SELECT app_id, strftime('%Y-%m-%d %H:00:00', clicked_at), COUNT(*)
+FROM click_events
+WHERE id > ?1 AND id <= ?2
+GROUP BY 1, 2
+ON CONFLICT (app_id, hour) DO UPDATE SET clicks = click_hourly.clicks + excluded.clicks
+`
+
+type RollupClicksHourlyParams struct {
+	AfterID int64 `json:"after_id"`
+	UpToID  int64 `json:"up_to_id"`
+}
+
+func (q *Queries) RollupClicksHourly(ctx context.Context, arg RollupClicksHourlyParams) error {
+	_, err := q.db.ExecContext(ctx, rollupClicksHourly, arg.AfterID, arg.UpToID)
+	return err
+}
+
+const setClickRollupState = `-- name: SetClickRollupState :exec
+UPDATE click_rollup_state SET last_event_id = ? WHERE id = 1
+`
+
+func (q *Queries) SetClickRollupState(ctx context.Context, lastEventID int64) error {
+	_, err := q.db.ExecContext(ctx, setClickRollupState, lastEventID)
+	return err
+}
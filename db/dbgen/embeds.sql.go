@@ -0,0 +1,65 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: embeds.sql
+
+package dbgen
+
+import (
+	"context"
+)
+
+const countEmbedView = `-- name: CountEmbedView :exec
+INSERT INTO embed_views (day, app_id, views)
+VALUES (date('now'), ?, 1)
+ON CONFLICT (day, app_id) DO UPDATE SET views = embed_views.views + 1
+`
+
+func (q *Queries) CountEmbedView(ctx context.Context, appID int64) error {
+	_, err := q.db.ExecContext(ctx, countEmbedView, appID)
+	return err
+}
+
+const listDailyEmbedViews = `-- name: ListDailyEmbedViews :many
+SELECT
+    app_id,
+    day,
+    views
+FROM embed_views
+WHERE day >= CAST(?1 AS TEXT) AND day <= CAST(?2 AS TEXT)
+ORDER BY day
+`
+
+type ListDailyEmbedViewsParams struct {
+	FromDay string `json:"from_day"`
+	ToDay   string `json:"to_day"`
+}
+
+type ListDailyEmbedViewsRow struct {
+	AppID int64  `json:"app_id"`
+	Day   string `json:"day"`
+	Views int64  `json:"views"`
+}
+
+func (q *Queries) ListDailyEmbedViews(ctx context.Context, arg ListDailyEmbedViewsParams) ([]ListDailyEmbedViewsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listDailyEmbedViews, arg.FromDay, arg.ToDay)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListDailyEmbedViewsRow{}
+	for rows.Next() {
+		var i ListDailyEmbedViewsRow
+		if err := rows.Scan(&i.AppID, &i.Day, &i.Views); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
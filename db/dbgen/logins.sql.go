@@ -0,0 +1,198 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: logins.sql
+
+package dbgen
+
+import (
+	"context"
+	"time"
+)
+
+const createLoginFailure = `-- name: CreateLoginFailure :exec
+INSERT INTO login_failures (ip, username, reason, created_at)
+VALUES (?, ?, ?, ?)
+`
+
+type CreateLoginFailureParams struct {
+	Ip        string    `json:"ip"`
+	Username  string    `json:"username"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (q *Queries) CreateLoginFailure(ctx context.Context, arg CreateLoginFailureParams) error {
+	_, err := q.db.ExecContext(ctx, createLoginFailure,
+		arg.Ip,
+		arg.Username,
+		arg.Reason,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const deleteLoginThrottle = `-- name: DeleteLoginThrottle :exec
+DELETE FROM login_throttles WHERE kind = ? AND key = ?
+`
+
+type DeleteLoginThrottleParams struct {
+	Kind string `json:"kind"`
+	Key  string `json:"key"`
+}
+
+func (q *Queries) DeleteLoginThrottle(ctx context.Context, arg DeleteLoginThrottleParams) error {
+	_, err := q.db.ExecContext(ctx, deleteLoginThrottle, arg.Kind, arg.Key)
+	return err
+}
+
+const deleteOldLoginFailures = `-- name: DeleteOldLoginFailures :exec
+DELETE FROM login_failures WHERE created_at < ?
+`
+
+func (q *Queries) DeleteOldLoginFailures(ctx context.Context, createdAt time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteOldLoginFailures, createdAt)
+	return err
+}
+
+const deleteStaleLoginThrottles = `-- name: DeleteStaleLoginThrottles :exec
+DELETE FROM login_throttles
+WHERE locked_until < ?1 AND last_failure_at < ?2
+`
+
+type DeleteStaleLoginThrottlesParams struct {
+	Now         time.Time `json:"now"`
+	WindowStart time.Time `json:"window_start"`
+}
+
+func (q *Queries) DeleteStaleLoginThrottles(ctx context.Context, arg DeleteStaleLoginThrottlesParams) error {
+	_, err := q.db.ExecContext(ctx, deleteStaleLoginThrottles, arg.Now, arg.WindowStart)
+	return err
+}
+
+const getLoginThrottle = `-- name: GetLoginThrottle :one
+SELECT kind, "key", failures, last_failure_at, locked_until FROM login_throttles WHERE kind = ? AND key = ?
+`
+
+type GetLoginThrottleParams struct {
+	Kind string `json:"kind"`
+	Key  string `json:"key"`
+}
+
+func (q *Queries) GetLoginThrottle(ctx context.Context, arg GetLoginThrottleParams) (LoginThrottle, error) {
+	row := q.db.QueryRowContext(ctx, getLoginThrottle, arg.Kind, arg.Key)
+	var i LoginThrottle
+	err := row.Scan(
+		&i.Kind,
+		&i.Key,
+		&i.Failures,
+		&i.LastFailureAt,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
+const listLoginFailures = `-- name: ListLoginFailures :many
+SELECT id, ip, username, reason, created_at FROM login_failures ORDER BY id DESC LIMIT ?
+`
+
+func (q *Queries) ListLoginFailures(ctx context.Context, limit int64) ([]LoginFailure, error) {
+	rows, err := q.db.QueryContext(ctx, listLoginFailures, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []LoginFailure{}
+	for rows.Next() {
+		var i LoginFailure
+		if err := rows.Scan(
+			&i.ID,
+			&i.Ip,
+			&i.Username,
+			&i.Reason,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLoginLockouts = `-- name: ListLoginLockouts :many
+SELECT kind, "key", failures, last_failure_at, locked_until FROM login_throttles
+WHERE locked_until > ?1
+ORDER BY locked_until DESC
+`
+
+func (q *Queries) ListLoginLockouts(ctx context.Context, now time.Time) ([]LoginThrottle, error) {
+	rows, err := q.db.QueryContext(ctx, listLoginLockouts, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []LoginThrottle{}
+	for rows.Next() {
+		var i LoginThrottle
+		if err := rows.Scan(
+			&i.Kind,
+			&i.Key,
+			&i.Failures,
+			&i.LastFailureAt,
+			&i.LockedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordLoginFailure = `-- name: RecordLoginFailure :one
+INSERT INTO login_throttles (kind, key, failures, last_failure_at, locked_until)
+VALUES (?1, ?2, 1, ?3, ?3)
+ON CONFLICT (kind, key) DO UPDATE SET
+    failures = login_throttles.failures + 1,
+    last_failure_at = excluded.last_failure_at
+RETURNING failures
+`
+
+type RecordLoginFailureParams struct {
+	Kind string    `json:"kind"`
+	Key  string    `json:"key"`
+	Now  time.Time `json:"now"`
+}
+
+func (q *Queries) RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, recordLoginFailure, arg.Kind, arg.Key, arg.Now)
+	var failures int64
+	err := row.Scan(&failures)
+	return failures, err
+}
+
+const setLoginLockedUntil = `-- name: SetLoginLockedUntil :exec
+UPDATE login_throttles SET locked_until = ? WHERE kind = ? AND key = ?
+`
+
+type SetLoginLockedUntilParams struct {
+	LockedUntil time.Time `json:"locked_until"`
+	Kind        string    `json:"kind"`
+	Key         string    `json:"key"`
+}
+
+func (q *Queries) SetLoginLockedUntil(ctx context.Context, arg SetLoginLockedUntilParams) error {
+	_, err := q.db.ExecContext(ctx, setLoginLockedUntil, arg.LockedUntil, arg.Kind, arg.Key)
+	return err
+}
@@ -8,6 +8,15 @@ import (
 	"time"
 )
 
+type AdminUser struct {
+	ID           int64      `json:"id"`
+	Username     string     `json:"username"`
+	PasswordHash string     `json:"password_hash"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastLoginAt  *time.Time `json:"last_login_at"`
+	Role         string     `json:"role"`
+}
+
 type App struct {
 	ID             int64     `json:"id"`
 	Url            string    `json:"url"`
@@ -20,6 +29,94 @@ type App struct {
 	UpdatedAt      time.Time `json:"updated_at"`
 	Prompt         *string   `json:"prompt"`
 	ClickCount     *int64    `json:"click_count"`
+	UtmSource      *string   `json:"utm_source"`
+	UtmMedium      *string   `json:"utm_medium"`
+	UtmCampaign    *string   `json:"utm_campaign"`
+	Pinned         bool      `json:"pinned"`
+	Slug           string    `json:"slug"`
+	Authors        string    `json:"authors"`
+	Version        string    `json:"version"`
+	Doi            string    `json:"doi"`
+}
+
+type AppSdg struct {
+	AppID int64 `json:"app_id"`
+	Goal  int64 `json:"goal"`
+}
+
+type AppTag struct {
+	AppID int64 `json:"app_id"`
+	TagID int64 `json:"tag_id"`
+}
+
+type AppsFt struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Prompt      string `json:"prompt"`
+}
+
+type ClickDaily struct {
+	AppID  int64  `json:"app_id"`
+	Day    string `json:"day"`
+	Clicks int64  `json:"clicks"`
+}
+
+type ClickEvent struct {
+	ID            int64     `json:"id"`
+	AppID         int64     `json:"app_id"`
+	ClickedAt     time.Time `json:"clicked_at"`
+	ReferrerClass string    `json:"referrer_class"`
+	DeviceClass   string    `json:"device_class"`
+	ReferrerHost  string    `json:"referrer_host"`
+}
+
+type ClickHourly struct {
+	AppID  int64  `json:"app_id"`
+	Hour   string `json:"hour"`
+	Clicks int64  `json:"clicks"`
+}
+
+type ClickRejection struct {
+	Day    string `json:"day"`
+	Reason string `json:"reason"`
+	Count  int64  `json:"count"`
+}
+
+type ClickRollupState struct {
+	ID          int64 `json:"id"`
+	LastEventID int64 `json:"last_event_id"`
+}
+
+type EmbedView struct {
+	Day   string `json:"day"`
+	AppID int64  `json:"app_id"`
+	Views int64  `json:"views"`
+}
+
+type Invite struct {
+	ID        string     `json:"id"`
+	Role      string     `json:"role"`
+	CreatedBy *int64     `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	UsedBy    *int64     `json:"used_by"`
+}
+
+type LoginFailure struct {
+	ID        int64     `json:"id"`
+	Ip        string    `json:"ip"`
+	Username  string    `json:"username"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type LoginThrottle struct {
+	Kind          string    `json:"kind"`
+	Key           string    `json:"key"`
+	Failures      int64     `json:"failures"`
+	LastFailureAt time.Time `json:"last_failure_at"`
+	LockedUntil   time.Time `json:"locked_until"`
 }
 
 type Migration struct {
@@ -28,9 +125,38 @@ type Migration struct {
 	ExecutedAt      time.Time `json:"executed_at"`
 }
 
+type QrScan struct {
+	Day   string `json:"day"`
+	AppID int64  `json:"app_id"`
+	Scans int64  `json:"scans"`
+}
+
+type Session struct {
+	ID        string    `json:"id"`
+	UserID    int64     `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type Setting struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type Tag struct {
+	ID   int64  `json:"id"`
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
 type Visitor struct {
 	ID        string    `json:"id"`
 	ViewCount int64     `json:"view_count"`
 	CreatedAt time.Time `json:"created_at"`
 	LastSeen  time.Time `json:"last_seen"`
 }
+
+type VisitorSalt struct {
+	Day  string `json:"day"`
+	Salt []byte `json:"salt"`
+}
@@ -0,0 +1,65 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: scans.sql
+
+package dbgen
+
+import (
+	"context"
+)
+
+const countQRScan = `-- name: CountQRScan :exec
+INSERT INTO qr_scans (day, app_id, scans)
+VALUES (date('now'), ?, 1)
+ON CONFLICT (day, app_id) DO UPDATE SET scans = qr_scans.scans + 1
+`
+
+func (q *Queries) CountQRScan(ctx context.Context, appID int64) error {
+	_, err := q.db.ExecContext(ctx, countQRScan, appID)
+	return err
+}
+
+const listDailyQRScans = `-- name: ListDailyQRScans :many
+SELECT
+    app_id,
+    day,
+    scans
+FROM qr_scans
+WHERE day >= CAST(?1 AS TEXT) AND day <= CAST(?2 AS TEXT)
+ORDER BY day
+`
+
+type ListDailyQRScansParams struct {
+	FromDay string `json:"from_day"`
+	ToDay   string `json:"to_day"`
+}
+
+type ListDailyQRScansRow struct {
+	AppID int64  `json:"app_id"`
+	Day   string `json:"day"`
+	Scans int64  `json:"scans"`
+}
+
+func (q *Queries) ListDailyQRScans(ctx context.Context, arg ListDailyQRScansParams) ([]ListDailyQRScansRow, error) {
+	rows, err := q.db.QueryContext(ctx, listDailyQRScans, arg.FromDay, arg.ToDay)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListDailyQRScansRow{}
+	for rows.Next() {
+		var i ListDailyQRScansRow
+		if err := rows.Scan(&i.AppID, &i.Day, &i.Scans); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,87 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: sdgs.sql
+
+package dbgen
+
+import (
+	"context"
+)
+
+const addAppSDG = `-- name: AddAppSDG :exec
+INSERT OR IGNORE INTO app_sdgs (app_id, goal) VALUES (?, ?)
+`
+
+type AddAppSDGParams struct {
+	AppID int64 `json:"app_id"`
+	Goal  int64 `json:"goal"`
+}
+
+func (q *Queries) AddAppSDG(ctx context.Context, arg AddAppSDGParams) error {
+	_, err := q.db.ExecContext(ctx, addAppSDG, arg.AppID, arg.Goal)
+	return err
+}
+
+const deleteAppSDGs = `-- name: DeleteAppSDGs :exec
+DELETE FROM app_sdgs WHERE app_id = ?
+`
+
+func (q *Queries) DeleteAppSDGs(ctx context.Context, appID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteAppSDGs, appID)
+	return err
+}
+
+const listAppSDGs = `-- name: ListAppSDGs :many
+SELECT app_id, goal FROM app_sdgs ORDER BY goal
+`
+
+func (q *Queries) ListAppSDGs(ctx context.Context) ([]AppSdg, error) {
+	rows, err := q.db.QueryContext(ctx, listAppSDGs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AppSdg{}
+	for rows.Next() {
+		var i AppSdg
+		if err := rows.Scan(&i.AppID, &i.Goal); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSDGsForApp = `-- name: ListSDGsForApp :many
+SELECT goal FROM app_sdgs WHERE app_id = ? ORDER BY goal
+`
+
+func (q *Queries) ListSDGsForApp(ctx context.Context, appID int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listSDGsForApp, appID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var goal int64
+		if err := rows.Scan(&goal); err != nil {
+			return nil, err
+		}
+		items = append(items, goal)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,242 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: stats.sql
+
+package dbgen
+
+import (
+	"context"
+)
+
+const listClickRejections = `-- name: ListClickRejections :many
+SELECT
+    reason,
+    CAST(SUM(count) AS INTEGER) AS count
+FROM click_rejections
+WHERE day >= ?1 AND day <= ?2
+GROUP BY reason
+ORDER BY 2 DESC
+`
+
+type ListClickRejectionsParams struct {
+	FromDay string `json:"from_day"`
+	ToDay   string `json:"to_day"`
+}
+
+type ListClickRejectionsRow struct {
+	Reason string `json:"reason"`
+	Count  int64  `json:"count"`
+}
+
+func (q *Queries) ListClickRejections(ctx context.Context, arg ListClickRejectionsParams) ([]ListClickRejectionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listClickRejections, arg.FromDay, arg.ToDay)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListClickRejectionsRow{}
+	for rows.Next() {
+		var i ListClickRejectionsRow
+		if err := rows.Scan(&i.Reason, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDailyClicks = `-- name: ListDailyClicks :many
+SELECT
+    app_id,
+    day,
+    CAST(SUM(clicks) AS INTEGER) AS clicks
+FROM (
+    SELECT app_id, day, clicks FROM click_daily
+    UNION ALL
+    SELECT app_id, date(clicked_at) AS day, 1 AS clicks FROM click_events
+    WHERE id > (SELECT last_event_id FROM click_rollup_state WHERE id = 1)
+) AS c
+WHERE day >= ?1 AND day <= ?2
+GROUP BY app_id, day
+ORDER BY day
+`
+
+type ListDailyClicksParams struct {
+	FromDay string `json:"from_day"`
+	ToDay   string `json:"to_day"`
+}
+
+type ListDailyClicksRow struct {
+	AppID  int64  `json:"app_id"`
+	Day    string `json:"day"`
+	Clicks int64  `json:"clicks"`
+}
+
+// Clicks per app and day in [from_day, to_day], including events the
+// background rollup has not reached yet.
+func (q *Queries) ListDailyClicks(ctx context.Context, arg ListDailyClicksParams) ([]ListDailyClicksRow, error) {
+	rows, err := q.db.QueryContext(ctx, listDailyClicks, arg.FromDay, arg.ToDay)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListDailyClicksRow{}
+	for rows.Next() {
+		var i ListDailyClicksRow
+		if err := rows.Scan(&i.AppID, &i.Day, &i.Clicks); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDailyVisitors = `-- name: ListDailyVisitors :many
+SELECT
+    CAST(date(created_at) AS TEXT) AS day,
+    COUNT(*) AS visitors,
+    CAST(SUM(view_count) AS INTEGER) AS views
+FROM visitors
+WHERE date(created_at) >= CAST(?1 AS TEXT) AND date(created_at) <= CAST(?2 AS TEXT)
+GROUP BY 1
+ORDER BY 1
+`
+
+type ListDailyVisitorsParams struct {
+	FromDay string `json:"from_day"`
+	ToDay   string `json:"to_day"`
+}
+
+type ListDailyVisitorsRow struct {
+	Day      string `json:"day"`
+	Visitors int64  `json:"visitors"`
+	Views    int64  `json:"views"`
+}
+
+// Visitor ids rotate daily, so each row in visitors belongs to one day.
+func (q *Queries) ListDailyVisitors(ctx context.Context, arg ListDailyVisitorsParams) ([]ListDailyVisitorsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listDailyVisitors, arg.FromDay, arg.ToDay)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListDailyVisitorsRow{}
+	for rows.Next() {
+		var i ListDailyVisitorsRow
+		if err := rows.Scan(&i.Day, &i.Visitors, &i.Views); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReferrerClasses = `-- name: ListReferrerClasses :many
+SELECT
+    referrer_class,
+    COUNT(*) AS clicks
+FROM click_events
+WHERE date(clicked_at) >= CAST(?1 AS TEXT) AND date(clicked_at) <= CAST(?2 AS TEXT)
+GROUP BY referrer_class
+ORDER BY clicks DESC
+`
+
+type ListReferrerClassesParams struct {
+	FromDay string `json:"from_day"`
+	ToDay   string `json:"to_day"`
+}
+
+type ListReferrerClassesRow struct {
+	ReferrerClass string `json:"referrer_class"`
+	Clicks        int64  `json:"clicks"`
+}
+
+func (q *Queries) ListReferrerClasses(ctx context.Context, arg ListReferrerClassesParams) ([]ListReferrerClassesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listReferrerClasses, arg.FromDay, arg.ToDay)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListReferrerClassesRow{}
+	for rows.Next() {
+		var i ListReferrerClassesRow
+		if err := rows.Scan(&i.ReferrerClass, &i.Clicks); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReferrerHosts = `-- name: ListReferrerHosts :many
+SELECT
+    referrer_host,
+    COUNT(*) AS clicks
+FROM click_events
+WHERE referrer_host != ''
+  AND date(clicked_at) >= CAST(?1 AS TEXT) AND date(clicked_at) <= CAST(?2 AS TEXT)
+GROUP BY referrer_host
+ORDER BY clicks DESC, referrer_host
+LIMIT ?3
+`
+
+type ListReferrerHostsParams struct {
+	FromDay  string `json:"from_day"`
+	ToDay    string `json:"to_day"`
+	MaxHosts int64  `json:"max_hosts"`
+}
+
+type ListReferrerHostsRow struct {
+	ReferrerHost string `json:"referrer_host"`
+	Clicks       int64  `json:"clicks"`
+}
+
+// The sites that sent the most clicks; direct and internal clicks have no
+// referrer host.
+func (q *Queries) ListReferrerHosts(ctx context.Context, arg ListReferrerHostsParams) ([]ListReferrerHostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listReferrerHosts, arg.FromDay, arg.ToDay, arg.MaxHosts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListReferrerHostsRow{}
+	for rows.Next() {
+		var i ListReferrerHostsRow
+		if err := rows.Scan(&i.ReferrerHost, &i.Clicks); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
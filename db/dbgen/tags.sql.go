@@ -0,0 +1,196 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: tags.sql
+
+package dbgen
+
+import (
+	"context"
+)
+
+const addAppTag = `-- name: AddAppTag :exec
+INSERT OR IGNORE INTO app_tags (app_id, tag_id) VALUES (?, ?)
+`
+
+type AddAppTagParams struct {
+	AppID int64 `json:"app_id"`
+	TagID int64 `json:"tag_id"`
+}
+
+func (q *Queries) AddAppTag(ctx context.Context, arg AddAppTagParams) error {
+	_, err := q.db.ExecContext(ctx, addAppTag, arg.AppID, arg.TagID)
+	return err
+}
+
+const deleteAppTags = `-- name: DeleteAppTags :exec
+DELETE FROM app_tags WHERE app_id = ?
+`
+
+func (q *Queries) DeleteAppTags(ctx context.Context, appID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteAppTags, appID)
+	return err
+}
+
+const deleteUnusedTags = `-- name: DeleteUnusedTags :exec
+DELETE FROM tags WHERE id NOT IN (SELECT tag_id FROM app_tags)
+`
+
+func (q *Queries) DeleteUnusedTags(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteUnusedTags)
+	return err
+}
+
+const getTagBySlug = `-- name: GetTagBySlug :one
+SELECT id, slug, name FROM tags WHERE slug = ?
+`
+
+func (q *Queries) GetTagBySlug(ctx context.Context, slug string) (Tag, error) {
+	row := q.db.QueryRowContext(ctx, getTagBySlug, slug)
+	var i Tag
+	err := row.Scan(&i.ID, &i.Slug, &i.Name)
+	return i, err
+}
+
+const listAppTags = `-- name: ListAppTags :many
+SELECT
+    app_tags.app_id,
+    tags.id,
+    tags.slug,
+    tags.name
+FROM app_tags
+JOIN tags ON tags.id = app_tags.tag_id
+ORDER BY tags.name
+`
+
+type ListAppTagsRow struct {
+	AppID int64  `json:"app_id"`
+	ID    int64  `json:"id"`
+	Slug  string `json:"slug"`
+	Name  string `json:"name"`
+}
+
+func (q *Queries) ListAppTags(ctx context.Context) ([]ListAppTagsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAppTags)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAppTagsRow{}
+	for rows.Next() {
+		var i ListAppTagsRow
+		if err := rows.Scan(
+			&i.AppID,
+			&i.ID,
+			&i.Slug,
+			&i.Name,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTagCounts = `-- name: ListTagCounts :many
+SELECT
+    tags.id,
+    tags.slug,
+    tags.name,
+    COUNT(app_tags.app_id) AS apps
+FROM tags
+LEFT JOIN app_tags ON app_tags.tag_id = tags.id
+GROUP BY tags.id
+ORDER BY tags.name
+`
+
+type ListTagCountsRow struct {
+	ID   int64  `json:"id"`
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+	Apps int64  `json:"apps"`
+}
+
+func (q *Queries) ListTagCounts(ctx context.Context) ([]ListTagCountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTagCounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTagCountsRow{}
+	for rows.Next() {
+		var i ListTagCountsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Slug,
+			&i.Name,
+			&i.Apps,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTagsForApp = `-- name: ListTagsForApp :many
+SELECT tags.id, tags.slug, tags.name
+FROM tags
+JOIN app_tags ON app_tags.tag_id = tags.id
+WHERE app_tags.app_id = ?
+ORDER BY tags.name
+`
+
+func (q *Queries) ListTagsForApp(ctx context.Context, appID int64) ([]Tag, error) {
+	rows, err := q.db.QueryContext(ctx, listTagsForApp, appID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Tag{}
+	for rows.Next() {
+		var i Tag
+		if err := rows.Scan(&i.ID, &i.Slug, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertTag = `-- name: UpsertTag :one
+INSERT INTO tags (slug, name)
+VALUES (?, ?)
+ON CONFLICT (slug) DO UPDATE SET slug = excluded.slug
+RETURNING id, slug, name
+`
+
+type UpsertTagParams struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+func (q *Queries) UpsertTag(ctx context.Context, arg UpsertTagParams) (Tag, error) {
+	row := q.db.QueryRowContext(ctx, upsertTag, arg.Slug, arg.Name)
+	var i Tag
+	err := row.Scan(&i.ID, &i.Slug, &i.Name)
+	return i, err
+}
@@ -10,6 +10,50 @@ import (
 	"time"
 )
 
+const deleteVisitorSaltsBefore = `-- name: DeleteVisitorSaltsBefore :exec
+DELETE FROM visitor_salts
+WHERE
+  day < ?
+`
+
+func (q *Queries) DeleteVisitorSaltsBefore(ctx context.Context, day string) error {
+	_, err := q.db.ExecContext(ctx, deleteVisitorSaltsBefore, day)
+	return err
+}
+
+const getVisitorSalt = `-- name: GetVisitorSalt :one
+SELECT
+  salt
+FROM
+  visitor_salts
+WHERE
+  day = ?
+`
+
+func (q *Queries) GetVisitorSalt(ctx context.Context, day string) ([]byte, error) {
+	row := q.db.QueryRowContext(ctx, getVisitorSalt, day)
+	var salt []byte
+	err := row.Scan(&salt)
+	return salt, err
+}
+
+const insertVisitorSalt = `-- name: InsertVisitorSalt :exec
+INSERT
+OR IGNORE INTO visitor_salts (day, salt)
+VALUES
+  (?, ?)
+`
+
+type InsertVisitorSaltParams struct {
+	Day  string `json:"day"`
+	Salt []byte `json:"salt"`
+}
+
+func (q *Queries) InsertVisitorSalt(ctx context.Context, arg InsertVisitorSaltParams) error {
+	_, err := q.db.ExecContext(ctx, insertVisitorSalt, arg.Day, arg.Salt)
+	return err
+}
+
 const upsertVisitor = `-- name: UpsertVisitor :exec
 INSERT INTO
   visitors (id, view_count, created_at, last_seen)
@@ -0,0 +1,43 @@
+-- Per-click event log with hourly and daily rollups
+CREATE TABLE IF NOT EXISTS click_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    app_id INTEGER NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    clicked_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    referrer_class TEXT NOT NULL,
+    device_class TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS click_events_app_time ON click_events (app_id, clicked_at);
+
+-- Hour buckets are 'YYYY-MM-DD HH:00:00' in UTC
+CREATE TABLE IF NOT EXISTS click_hourly (
+    app_id INTEGER NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    hour TEXT NOT NULL,
+    clicks INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (app_id, hour)
+);
+
+-- Day buckets are 'YYYY-MM-DD' in UTC
+CREATE TABLE IF NOT EXISTS click_daily (
+    app_id INTEGER NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    day TEXT NOT NULL,
+    clicks INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (app_id, day)
+);
+
+-- Highest click_events.id already folded into the rollup tables
+CREATE TABLE IF NOT EXISTS click_rollup_state (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    last_event_id INTEGER NOT NULL
+);
+
+INSERT OR IGNORE INTO click_rollup_state (id, last_event_id) VALUES (1, 0);
+
+-- Legacy counters predate the event log. Book them on a sentinel day so
+-- they count towards the all-time total but not the 7/30 day windows.
+INSERT OR IGNORE INTO click_daily (app_id, day, clicks)
+SELECT id, '1970-01-01', click_count FROM apps WHERE click_count > 0;
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (005, '005-click-events');
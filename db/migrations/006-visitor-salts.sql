@@ -0,0 +1,10 @@
+-- Daily salts for anonymous visitor ids. Only the current day's salt is
+-- kept; older ones are deleted so yesterday's ids can't be recomputed.
+CREATE TABLE IF NOT EXISTS visitor_salts (
+    day TEXT PRIMARY KEY,
+    salt BLOB NOT NULL
+);
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (006, '006-visitor-salts');
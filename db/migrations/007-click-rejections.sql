@@ -0,0 +1,11 @@
+-- Clicks dropped by the abuse filters, per UTC day and reason
+CREATE TABLE IF NOT EXISTS click_rejections (
+    day TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    count INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, reason)
+);
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (007, '007-click-rejections');
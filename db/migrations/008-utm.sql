@@ -0,0 +1,8 @@
+-- Optional UTM parameters appended to outbound /go/{id} redirects
+ALTER TABLE apps ADD COLUMN utm_source TEXT;
+ALTER TABLE apps ADD COLUMN utm_medium TEXT;
+ALTER TABLE apps ADD COLUMN utm_campaign TEXT;
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (008, '008-utm');
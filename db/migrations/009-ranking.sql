@@ -0,0 +1,11 @@
+-- Pinned apps and site-wide settings such as the homepage ranking
+ALTER TABLE apps ADD COLUMN pinned BOOLEAN NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS settings (
+    key TEXT PRIMARY KEY,
+    value TEXT NOT NULL
+);
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (009, '009-ranking');
@@ -0,0 +1,47 @@
+-- Full-text search over apps. Umlauts and ß are spelled out (ä -> ae,
+-- ß -> ss) before indexing so that "Duerre" and "Dürre" find the same
+-- app; searchQuery in srv applies the same folding to queries. The
+-- tokenizer then takes care of case and remaining diacritics.
+CREATE VIRTUAL TABLE IF NOT EXISTS apps_fts USING fts5(
+    title,
+    description,
+    prompt,
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER IF NOT EXISTS apps_fts_insert AFTER INSERT ON apps BEGIN
+    INSERT INTO apps_fts (rowid, title, description, prompt)
+    VALUES (
+        new.id,
+        replace(replace(replace(replace(replace(replace(replace(replace(new.title, 'ä', 'ae'), 'ö', 'oe'), 'ü', 'ue'), 'Ä', 'Ae'), 'Ö', 'Oe'), 'Ü', 'Ue'), 'ß', 'ss'), 'ẞ', 'SS'),
+        replace(replace(replace(replace(replace(replace(replace(replace(new.description, 'ä', 'ae'), 'ö', 'oe'), 'ü', 'ue'), 'Ä', 'Ae'), 'Ö', 'Oe'), 'Ü', 'Ue'), 'ß', 'ss'), 'ẞ', 'SS'),
+        replace(replace(replace(replace(replace(replace(replace(replace(coalesce(new.prompt, ''), 'ä', 'ae'), 'ö', 'oe'), 'ü', 'ue'), 'Ä', 'Ae'), 'Ö', 'Oe'), 'Ü', 'Ue'), 'ß', 'ss'), 'ẞ', 'SS')
+    );
+END;
+
+CREATE TRIGGER IF NOT EXISTS apps_fts_update AFTER UPDATE OF title, description, prompt ON apps BEGIN
+    DELETE FROM apps_fts WHERE rowid = old.id;
+    INSERT INTO apps_fts (rowid, title, description, prompt)
+    VALUES (
+        new.id,
+        replace(replace(replace(replace(replace(replace(replace(replace(new.title, 'ä', 'ae'), 'ö', 'oe'), 'ü', 'ue'), 'Ä', 'Ae'), 'Ö', 'Oe'), 'Ü', 'Ue'), 'ß', 'ss'), 'ẞ', 'SS'),
+        replace(replace(replace(replace(replace(replace(replace(replace(new.description, 'ä', 'ae'), 'ö', 'oe'), 'ü', 'ue'), 'Ä', 'Ae'), 'Ö', 'Oe'), 'Ü', 'Ue'), 'ß', 'ss'), 'ẞ', 'SS'),
+        replace(replace(replace(replace(replace(replace(replace(replace(coalesce(new.prompt, ''), 'ä', 'ae'), 'ö', 'oe'), 'ü', 'ue'), 'Ä', 'Ae'), 'Ö', 'Oe'), 'Ü', 'Ue'), 'ß', 'ss'), 'ẞ', 'SS')
+    );
+END;
+
+CREATE TRIGGER IF NOT EXISTS apps_fts_delete AFTER DELETE ON apps BEGIN
+    DELETE FROM apps_fts WHERE rowid = old.id;
+END;
+
+INSERT INTO apps_fts (rowid, title, description, prompt)
+SELECT
+    id,
+    replace(replace(replace(replace(replace(replace(replace(replace(title, 'ä', 'ae'), 'ö', 'oe'), 'ü', 'ue'), 'Ä', 'Ae'), 'Ö', 'Oe'), 'Ü', 'Ue'), 'ß', 'ss'), 'ẞ', 'SS'),
+    replace(replace(replace(replace(replace(replace(replace(replace(description, 'ä', 'ae'), 'ö', 'oe'), 'ü', 'ue'), 'Ä', 'Ae'), 'Ö', 'Oe'), 'Ü', 'Ue'), 'ß', 'ss'), 'ẞ', 'SS'),
+    replace(replace(replace(replace(replace(replace(replace(replace(coalesce(prompt, ''), 'ä', 'ae'), 'ö', 'oe'), 'ü', 'ue'), 'Ä', 'Ae'), 'Ö', 'Oe'), 'Ü', 'Ue'), 'ß', 'ss'), 'ẞ', 'SS')
+FROM apps;
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (010, '010-search');
@@ -0,0 +1,43 @@
+-- Tags for grouping apps by theme
+CREATE TABLE IF NOT EXISTS tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    slug TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS app_tags (
+    app_id INTEGER NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (app_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS app_tags_tag ON app_tags (tag_id);
+
+INSERT OR IGNORE INTO tags (slug, name) VALUES
+    ('environment', 'Environment'),
+    ('health', 'Health'),
+    ('education', 'Education'),
+    ('energy', 'Energy'),
+    ('agriculture', 'Agriculture');
+
+-- Tag the seeded showcase apps; fresh databases get the same tags from
+-- seedApps.
+INSERT OR IGNORE INTO app_tags (app_id, tag_id)
+SELECT apps.id, tags.id
+FROM apps
+JOIN (
+    SELECT 'https://holzeinschlag-at.exe.xyz/' AS url, 'environment' AS slug
+    UNION ALL SELECT 'https://groundwater-at.exe.xyz/', 'environment'
+    UNION ALL SELECT 'https://groundwater-at.exe.xyz/', 'energy'
+    UNION ALL SELECT 'https://msf-prep.exe.xyz/', 'health'
+    UNION ALL SELECT 'https://schools-at.exe.xyz/', 'education'
+    UNION ALL SELECT 'https://maternity-ward-closure.exe.xyz/', 'health'
+    UNION ALL SELECT 'https://child-care-access-at.exe.xyz/', 'education'
+    UNION ALL SELECT 'https://austria-power.exe.xyz/', 'energy'
+    UNION ALL SELECT 'https://farm-subsidies-austria.exe.xyz/', 'agriculture'
+) AS seed ON seed.url = apps.url
+JOIN tags ON tags.slug = seed.slug;
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (011, '011-tags');
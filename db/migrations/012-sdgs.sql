@@ -0,0 +1,33 @@
+-- UN Sustainable Development Goals addressed by each app. The goals
+-- themselves (names, colours) are built into the server.
+CREATE TABLE IF NOT EXISTS app_sdgs (
+    app_id INTEGER NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    goal INTEGER NOT NULL CHECK (goal BETWEEN 1 AND 17),
+    PRIMARY KEY (app_id, goal)
+);
+
+CREATE INDEX IF NOT EXISTS app_sdgs_goal ON app_sdgs (goal);
+
+-- Map the seeded showcase apps; fresh databases get the same goals from
+-- seedApps.
+INSERT OR IGNORE INTO app_sdgs (app_id, goal)
+SELECT apps.id, seed.goal
+FROM apps
+JOIN (
+    SELECT 'https://holzeinschlag-at.exe.xyz/' AS url, 13 AS goal
+    UNION ALL SELECT 'https://holzeinschlag-at.exe.xyz/', 15
+    UNION ALL SELECT 'https://groundwater-at.exe.xyz/', 6
+    UNION ALL SELECT 'https://groundwater-at.exe.xyz/', 7
+    UNION ALL SELECT 'https://msf-prep.exe.xyz/', 3
+    UNION ALL SELECT 'https://schools-at.exe.xyz/', 4
+    UNION ALL SELECT 'https://maternity-ward-closure.exe.xyz/', 3
+    UNION ALL SELECT 'https://child-care-access-at.exe.xyz/', 4
+    UNION ALL SELECT 'https://child-care-access-at.exe.xyz/', 5
+    UNION ALL SELECT 'https://austria-power.exe.xyz/', 7
+    UNION ALL SELECT 'https://austria-power.exe.xyz/', 13
+    UNION ALL SELECT 'https://farm-subsidies-austria.exe.xyz/', 2
+) AS seed ON seed.url = apps.url;
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (012, '012-sdgs');
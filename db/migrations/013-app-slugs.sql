@@ -0,0 +1,9 @@
+-- URL slugs for app detail pages (/apps/{slug}). Existing apps get a slug
+-- generated from their title when the server starts.
+ALTER TABLE apps ADD COLUMN slug TEXT NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS apps_slug ON apps (slug) WHERE slug <> '';
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (013, '013-app-slugs');
@@ -0,0 +1,12 @@
+-- Views of the embeddable card (/embed) per UTC day and app, kept apart
+-- from clicks on the showcase page
+CREATE TABLE IF NOT EXISTS embed_views (
+    day TEXT NOT NULL,
+    app_id INTEGER NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    views INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, app_id)
+);
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (014, '014-embed-views');
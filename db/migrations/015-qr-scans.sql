@@ -0,0 +1,12 @@
+-- QR code scans (/go/{id}?src=qr) per UTC day and app, kept apart from
+-- card clicks
+CREATE TABLE IF NOT EXISTS qr_scans (
+    day TEXT NOT NULL,
+    app_id INTEGER NOT NULL REFERENCES apps(id) ON DELETE CASCADE,
+    scans INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, app_id)
+);
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (015, '015-qr-scans');
@@ -0,0 +1,9 @@
+-- Citation metadata for /apps/{id}/cite.*. authors holds one author per
+-- line, "Family, Given" for people or a plain name for organisations.
+ALTER TABLE apps ADD COLUMN authors TEXT NOT NULL DEFAULT '';
+ALTER TABLE apps ADD COLUMN version TEXT NOT NULL DEFAULT '';
+ALTER TABLE apps ADD COLUMN doi TEXT NOT NULL DEFAULT '';
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (016, '016-citations');
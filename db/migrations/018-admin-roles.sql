@@ -0,0 +1,20 @@
+-- Admin roles and invitations. Accounts that existed before roles had
+-- full access, so they become owners.
+ALTER TABLE admin_users ADD COLUMN role TEXT NOT NULL DEFAULT 'owner'
+    CHECK (role IN ('viewer', 'editor', 'owner'));
+
+-- Invite links for new admins. Like sessions, invites are keyed by the
+-- SHA-256 of the token in the link.
+CREATE TABLE IF NOT EXISTS invites (
+    id TEXT PRIMARY KEY,
+    role TEXT NOT NULL CHECK (role IN ('viewer', 'editor', 'owner')),
+    created_by INTEGER REFERENCES admin_users (id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    used_by INTEGER REFERENCES admin_users (id) ON DELETE SET NULL
+);
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (018, '018-admin-roles');
@@ -0,0 +1,27 @@
+-- Failed admin logins, counted per client IP and per username. After a
+-- few failures each further one locks the key out for twice as long, up
+-- to a cap; the counter restarts after a quiet period or a good login.
+CREATE TABLE IF NOT EXISTS login_throttles (
+    kind TEXT NOT NULL CHECK (kind IN ('ip', 'username')),
+    key TEXT NOT NULL,
+    failures INTEGER NOT NULL,
+    last_failure_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP NOT NULL,
+    PRIMARY KEY (kind, key)
+);
+
+-- Audit log of failed logins: wrong credentials, and attempts made while
+-- locked out.
+CREATE TABLE IF NOT EXISTS login_failures (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    ip TEXT NOT NULL,
+    username TEXT NOT NULL,
+    reason TEXT NOT NULL CHECK (reason IN ('password', 'locked')),
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS login_failures_created_at ON login_failures (created_at);
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (019, '019-login-throttle');
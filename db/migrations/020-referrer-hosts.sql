@@ -0,0 +1,8 @@
+-- The host a click came from, next to its coarse class, for the top
+-- referrers on the stats page. Empty for direct and internal clicks, and
+-- for clicks logged before this column existed.
+ALTER TABLE click_events ADD COLUMN referrer_host TEXT NOT NULL DEFAULT '';
+
+-- Record execution of this migration
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (020, '020-referrer-hosts');
@@ -1,28 +1,48 @@
 -- name: ListApps :many
-SELECT * FROM apps ORDER BY click_count DESC, sort_order ASC, id ASC;
+SELECT * FROM apps ORDER BY sort_order ASC, id ASC;
 
 -- name: GetApp :one
 SELECT * FROM apps WHERE id = ?;
 
+-- name: GetAppBySlug :one
+SELECT * FROM apps WHERE slug = ?;
+
+-- name: ListAppsWithoutSlug :many
+SELECT * FROM apps WHERE slug = '' ORDER BY id;
+
+-- name: SetAppSlug :exec
+UPDATE apps SET slug = ? WHERE id = ?;
+
 -- name: CreateApp :one
-INSERT INTO apps (url, title, description, shelley_command, thumbnail, sort_order, prompt, created_at, updated_at)
-VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+INSERT INTO apps (url, title, slug, description, shelley_command, thumbnail, sort_order, prompt, utm_source, utm_medium, utm_campaign, pinned, authors, version, doi, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
 RETURNING *;
 
 -- name: UpdateApp :exec
 UPDATE apps SET
     url = ?,
     title = ?,
+    slug = ?,
     description = ?,
     shelley_command = ?,
     thumbnail = ?,
     sort_order = ?,
     prompt = ?,
+    utm_source = ?,
+    utm_medium = ?,
+    utm_campaign = ?,
+    pinned = ?,
+    authors = ?,
+    version = ?,
+    doi = ?,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ?;
 
 -- name: DeleteApp :exec
 DELETE FROM apps WHERE id = ?;
 
--- name: IncrementClickCount :exec
-UPDATE apps SET click_count = click_count + 1 WHERE id = ?;
+-- name: AddClickCount :exec
+UPDATE apps SET click_count = click_count + CAST(sqlc.arg(clicks) AS INTEGER) WHERE id = sqlc.arg(id);
+
+-- name: ListNewestApps :many
+SELECT * FROM apps ORDER BY created_at DESC, id DESC LIMIT ?;
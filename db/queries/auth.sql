@@ -0,0 +1,66 @@
+-- name: CountAdminUsers :one
+SELECT COUNT(*) FROM admin_users;
+
+-- name: CreateAdminUser :one
+INSERT INTO admin_users (username, password_hash, role)
+VALUES (?, ?, ?)
+RETURNING *;
+
+-- name: ListAdminUsers :many
+SELECT * FROM admin_users ORDER BY username;
+
+-- name: GetAdminUser :one
+SELECT * FROM admin_users WHERE id = ?;
+
+-- name: SetAdminUserRole :exec
+UPDATE admin_users SET role = ? WHERE id = ?;
+
+-- name: DeleteAdminUser :exec
+DELETE FROM admin_users WHERE id = ?;
+
+-- name: CountAdminOwners :one
+SELECT COUNT(*) FROM admin_users WHERE role = 'owner';
+
+-- name: GetAdminUserByName :one
+SELECT * FROM admin_users WHERE username = ?;
+
+-- name: SetAdminLastLogin :exec
+UPDATE admin_users SET last_login_at = ? WHERE id = ?;
+
+-- name: CreateSession :exec
+INSERT INTO sessions (id, user_id, created_at, expires_at)
+VALUES (?, ?, ?, ?);
+
+-- name: GetSessionUser :one
+SELECT admin_users.*
+FROM sessions
+JOIN admin_users ON admin_users.id = sessions.user_id
+WHERE sessions.id = ? AND sessions.expires_at > sqlc.arg(now);
+
+-- name: DeleteSession :exec
+DELETE FROM sessions WHERE id = ?;
+
+-- name: DeleteExpiredSessions :exec
+DELETE FROM sessions WHERE expires_at <= ?;
+
+-- name: CreateInvite :exec
+INSERT INTO invites (id, role, created_by, created_at, expires_at)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: GetOpenInvite :one
+SELECT * FROM invites
+WHERE id = ? AND used_at IS NULL AND expires_at > sqlc.arg(now);
+
+-- name: UseInvite :execrows
+UPDATE invites SET used_at = sqlc.arg(used_at), used_by = sqlc.arg(used_by)
+WHERE id = sqlc.arg(id) AND used_at IS NULL AND expires_at > sqlc.arg(now);
+
+-- name: ListOpenInvites :many
+SELECT invites.*, admin_users.username AS created_by_name
+FROM invites
+LEFT JOIN admin_users ON admin_users.id = invites.created_by
+WHERE invites.used_at IS NULL AND invites.expires_at > sqlc.arg(now)
+ORDER BY invites.created_at DESC;
+
+-- name: DeleteInvite :exec
+DELETE FROM invites WHERE id = ?;
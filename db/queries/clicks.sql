@@ -0,0 +1,75 @@
+-- name: InsertClickEvent :exec
+-- Selecting from apps skips clicks on apps deleted since they were queued.
+INSERT INTO click_events (app_id, clicked_at, referrer_class, referrer_host, device_class)
+SELECT apps.id, CAST(sqlc.arg(clicked_at) AS TEXT), CAST(sqlc.arg(referrer_class) AS TEXT), CAST(sqlc.arg(referrer_host) AS TEXT), CAST(sqlc.arg(device_class) AS TEXT)
+FROM apps
+WHERE apps.id = sqlc.arg(app_id);
+
+-- name: GetClickRollupState :one
+SELECT last_event_id FROM click_rollup_state WHERE id = 1;
+
+-- name: MaxClickEventID :one
+SELECT CAST(COALESCE(MAX(id), 0) AS INTEGER) AS max_id FROM click_events;
+
+-- name: RollupClicksHourly :exec
+INSERT INTO click_hourly (app_id, hour, clicks)
+SELECT app_id, strftime('%Y-%m-%d %H:00:00', clicked_at), COUNT(*)
+FROM click_events
+WHERE id > sqlc.arg(after_id) AND id <= sqlc.arg(up_to_id)
+GROUP BY 1, 2
+ON CONFLICT (app_id, hour) DO UPDATE SET clicks = click_hourly.clicks + excluded.clicks;
+
+-- name: RollupClicksDaily :exec
+INSERT INTO click_daily (app_id, day, clicks)
+SELECT app_id, date(clicked_at), COUNT(*)
+FROM click_events
+WHERE id > sqlc.arg(after_id) AND id <= sqlc.arg(up_to_id)
+GROUP BY 1, 2
+ON CONFLICT (app_id, day) DO UPDATE SET clicks = click_daily.clicks + excluded.clicks;
+
+-- name: SetClickRollupState :exec
+UPDATE click_rollup_state SET last_event_id = ? WHERE id = 1;
+
+-- name: ListClickStats :many
+-- Clicks per app over the last 7 and 30 days and in total. Events that the
+-- background rollup has not reached yet are counted straight from the log.
+SELECT
+    app_id,
+    CAST(COALESCE(SUM(CASE WHEN day >= date('now', '-6 days') THEN clicks ELSE 0 END), 0) AS INTEGER) AS clicks_7d,
+    CAST(COALESCE(SUM(CASE WHEN day >= date('now', '-29 days') THEN clicks ELSE 0 END), 0) AS INTEGER) AS clicks_30d,
+    CAST(COALESCE(SUM(clicks), 0) AS INTEGER) AS clicks_total
+FROM (
+    SELECT app_id, day, clicks FROM click_daily
+    UNION ALL
+    SELECT app_id, date(clicked_at) AS day, 1 AS clicks FROM click_events
+    WHERE id > (SELECT last_event_id FROM click_rollup_state WHERE id = 1)
+) AS c
+GROUP BY app_id;
+
+-- name: CountClickRejection :exec
+INSERT INTO click_rejections (day, reason, count)
+VALUES (date('now'), ?, 1)
+ON CONFLICT (day, reason) DO UPDATE SET count = click_rejections.count + 1;
+
+-- name: AddClickRejections :exec
+INSERT INTO click_rejections (day, reason, count)
+VALUES (date('now'), sqlc.arg(reason), sqlc.arg(count))
+ON CONFLICT (day, reason) DO UPDATE SET count = click_rejections.count + excluded.count;
+
+-- name: ListClickScores :many
+-- Time-decayed popularity: every click counts exp(-decay * age in hours),
+-- so a click loses half its weight each half-life. Clicks older than 90
+-- days are ignored. That includes the legacy totals booked on 1970-01-01
+-- in click_daily: they have no real date, and popularity is about recent
+-- interest, so they only show up in the all-time click counts.
+SELECT
+    app_id,
+    CAST(SUM(clicks * exp(-(julianday('now') - julianday(hour)) * 24 * CAST(sqlc.arg(decay_per_hour) AS REAL))) AS REAL) AS score
+FROM (
+    SELECT app_id, hour, clicks FROM click_hourly
+    UNION ALL
+    SELECT app_id, strftime('%Y-%m-%d %H:00:00', clicked_at) AS hour, 1 AS clicks FROM click_events
+    WHERE id > (SELECT last_event_id FROM click_rollup_state WHERE id = 1)
+) AS c
+WHERE hour >= datetime('now', '-90 days')
+GROUP BY app_id;
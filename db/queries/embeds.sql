@@ -0,0 +1,13 @@
+-- name: CountEmbedView :exec
+INSERT INTO embed_views (day, app_id, views)
+VALUES (date('now'), ?, 1)
+ON CONFLICT (day, app_id) DO UPDATE SET views = embed_views.views + 1;
+
+-- name: ListDailyEmbedViews :many
+SELECT
+    app_id,
+    day,
+    views
+FROM embed_views
+WHERE day >= CAST(sqlc.arg(from_day) AS TEXT) AND day <= CAST(sqlc.arg(to_day) AS TEXT)
+ORDER BY day;
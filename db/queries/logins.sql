@@ -0,0 +1,35 @@
+-- name: GetLoginThrottle :one
+SELECT * FROM login_throttles WHERE kind = ? AND key = ?;
+
+-- name: RecordLoginFailure :one
+INSERT INTO login_throttles (kind, key, failures, last_failure_at, locked_until)
+VALUES (sqlc.arg(kind), sqlc.arg(key), 1, sqlc.arg(now), sqlc.arg(now))
+ON CONFLICT (kind, key) DO UPDATE SET
+    failures = login_throttles.failures + 1,
+    last_failure_at = excluded.last_failure_at
+RETURNING failures;
+
+-- name: SetLoginLockedUntil :exec
+UPDATE login_throttles SET locked_until = ? WHERE kind = ? AND key = ?;
+
+-- name: DeleteLoginThrottle :exec
+DELETE FROM login_throttles WHERE kind = ? AND key = ?;
+
+-- name: DeleteStaleLoginThrottles :exec
+DELETE FROM login_throttles
+WHERE locked_until < sqlc.arg(now) AND last_failure_at < sqlc.arg(window_start);
+
+-- name: ListLoginLockouts :many
+SELECT * FROM login_throttles
+WHERE locked_until > sqlc.arg(now)
+ORDER BY locked_until DESC;
+
+-- name: CreateLoginFailure :exec
+INSERT INTO login_failures (ip, username, reason, created_at)
+VALUES (?, ?, ?, ?);
+
+-- name: ListLoginFailures :many
+SELECT * FROM login_failures ORDER BY id DESC LIMIT ?;
+
+-- name: DeleteOldLoginFailures :exec
+DELETE FROM login_failures WHERE created_at < ?;
@@ -0,0 +1,13 @@
+-- name: CountQRScan :exec
+INSERT INTO qr_scans (day, app_id, scans)
+VALUES (date('now'), ?, 1)
+ON CONFLICT (day, app_id) DO UPDATE SET scans = qr_scans.scans + 1;
+
+-- name: ListDailyQRScans :many
+SELECT
+    app_id,
+    day,
+    scans
+FROM qr_scans
+WHERE day >= CAST(sqlc.arg(from_day) AS TEXT) AND day <= CAST(sqlc.arg(to_day) AS TEXT)
+ORDER BY day;
@@ -0,0 +1,11 @@
+-- name: ListAppSDGs :many
+SELECT app_id, goal FROM app_sdgs ORDER BY goal;
+
+-- name: ListSDGsForApp :many
+SELECT goal FROM app_sdgs WHERE app_id = ? ORDER BY goal;
+
+-- name: DeleteAppSDGs :exec
+DELETE FROM app_sdgs WHERE app_id = ?;
+
+-- name: AddAppSDG :exec
+INSERT OR IGNORE INTO app_sdgs (app_id, goal) VALUES (?, ?);
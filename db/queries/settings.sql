@@ -0,0 +1,7 @@
+-- name: GetSetting :one
+SELECT value FROM settings WHERE key = ?;
+
+-- name: SetSetting :exec
+INSERT INTO settings (key, value)
+VALUES (?, ?)
+ON CONFLICT (key) DO UPDATE SET value = excluded.value;
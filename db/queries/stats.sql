@@ -0,0 +1,58 @@
+-- name: ListDailyClicks :many
+-- Clicks per app and day in [from_day, to_day], including events the
+-- background rollup has not reached yet.
+SELECT
+    app_id,
+    day,
+    CAST(SUM(clicks) AS INTEGER) AS clicks
+FROM (
+    SELECT app_id, day, clicks FROM click_daily
+    UNION ALL
+    SELECT app_id, date(clicked_at) AS day, 1 AS clicks FROM click_events
+    WHERE id > (SELECT last_event_id FROM click_rollup_state WHERE id = 1)
+) AS c
+WHERE day >= sqlc.arg(from_day) AND day <= sqlc.arg(to_day)
+GROUP BY app_id, day
+ORDER BY day;
+
+-- name: ListDailyVisitors :many
+-- Visitor ids rotate daily, so each row in visitors belongs to one day.
+SELECT
+    CAST(date(created_at) AS TEXT) AS day,
+    COUNT(*) AS visitors,
+    CAST(SUM(view_count) AS INTEGER) AS views
+FROM visitors
+WHERE date(created_at) >= CAST(sqlc.arg(from_day) AS TEXT) AND date(created_at) <= CAST(sqlc.arg(to_day) AS TEXT)
+GROUP BY 1
+ORDER BY 1;
+
+-- name: ListReferrerClasses :many
+SELECT
+    referrer_class,
+    COUNT(*) AS clicks
+FROM click_events
+WHERE date(clicked_at) >= CAST(sqlc.arg(from_day) AS TEXT) AND date(clicked_at) <= CAST(sqlc.arg(to_day) AS TEXT)
+GROUP BY referrer_class
+ORDER BY clicks DESC;
+
+-- name: ListReferrerHosts :many
+-- The sites that sent the most clicks; direct and internal clicks have no
+-- referrer host.
+SELECT
+    referrer_host,
+    COUNT(*) AS clicks
+FROM click_events
+WHERE referrer_host != ''
+  AND date(clicked_at) >= CAST(sqlc.arg(from_day) AS TEXT) AND date(clicked_at) <= CAST(sqlc.arg(to_day) AS TEXT)
+GROUP BY referrer_host
+ORDER BY clicks DESC, referrer_host
+LIMIT sqlc.arg(max_hosts);
+
+-- name: ListClickRejections :many
+SELECT
+    reason,
+    CAST(SUM(count) AS INTEGER) AS count
+FROM click_rejections
+WHERE day >= sqlc.arg(from_day) AND day <= sqlc.arg(to_day)
+GROUP BY reason
+ORDER BY 2 DESC;
@@ -0,0 +1,45 @@
+-- name: ListTagCounts :many
+SELECT
+    tags.id,
+    tags.slug,
+    tags.name,
+    COUNT(app_tags.app_id) AS apps
+FROM tags
+LEFT JOIN app_tags ON app_tags.tag_id = tags.id
+GROUP BY tags.id
+ORDER BY tags.name;
+
+-- name: GetTagBySlug :one
+SELECT * FROM tags WHERE slug = ?;
+
+-- name: UpsertTag :one
+INSERT INTO tags (slug, name)
+VALUES (?, ?)
+ON CONFLICT (slug) DO UPDATE SET slug = excluded.slug
+RETURNING *;
+
+-- name: ListAppTags :many
+SELECT
+    app_tags.app_id,
+    tags.id,
+    tags.slug,
+    tags.name
+FROM app_tags
+JOIN tags ON tags.id = app_tags.tag_id
+ORDER BY tags.name;
+
+-- name: ListTagsForApp :many
+SELECT tags.*
+FROM tags
+JOIN app_tags ON app_tags.tag_id = tags.id
+WHERE app_tags.app_id = ?
+ORDER BY tags.name;
+
+-- name: DeleteAppTags :exec
+DELETE FROM app_tags WHERE app_id = ?;
+
+-- name: AddAppTag :exec
+INSERT OR IGNORE INTO app_tags (app_id, tag_id) VALUES (?, ?);
+
+-- name: DeleteUnusedTags :exec
+DELETE FROM tags WHERE id NOT IN (SELECT tag_id FROM app_tags);
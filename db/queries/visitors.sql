@@ -15,3 +15,22 @@ FROM
   visitors
 WHERE
   id = ?;
+
+-- name: GetVisitorSalt :one
+SELECT
+  salt
+FROM
+  visitor_salts
+WHERE
+  day = ?;
+
+-- name: InsertVisitorSalt :exec
+INSERT
+OR IGNORE INTO visitor_salts (day, salt)
+VALUES
+  (?, ?);
+
+-- name: DeleteVisitorSaltsBefore :exec
+DELETE FROM visitor_salts
+WHERE
+  day < ?;
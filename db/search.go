@@ -0,0 +1,43 @@
+package db
+
+import (
+	"context"
+
+	"srv.exe.dev/db/dbgen"
+)
+
+// SearchResult is one full-text match; lower Score is more relevant.
+type SearchResult struct {
+	AppID int64
+	Score float64
+}
+
+// sqlc cannot resolve FTS5's table-named MATCH column, so this query lives
+// here instead of in queries/.
+const searchApps = `
+SELECT rowid, bm25(apps_fts, 10.0, 4.0, 1.0) AS score
+FROM apps_fts
+WHERE apps_fts MATCH ?
+ORDER BY score
+`
+
+// SearchApps runs an FTS5 match expression against title, description and
+// prompt, best matches first. Title hits weigh most, prompt hits least.
+// The expression must already be folded the way migration 010 folds the
+// indexed text.
+func SearchApps(ctx context.Context, q dbgen.DBTX, match string) ([]SearchResult, error) {
+	rows, err := q.QueryContext(ctx, searchApps, match)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var results []SearchResult
+	for rows.Next() {
+		var r SearchResult
+		if err := rows.Scan(&r.AppID, &r.Score); err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
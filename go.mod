@@ -2,7 +2,14 @@ module srv.exe.dev
 
 go 1.25.5
 
-require modernc.org/sqlite v1.39.0
+require (
+	github.com/go-pdf/fpdf v0.9.0
+	golang.org/x/crypto v0.39.0
+	golang.org/x/image v0.30.0
+	golang.org/x/term v0.32.0
+	modernc.org/sqlite v1.39.0
+	rsc.io/qr v0.2.0
+)
 
 require (
 	cel.dev/expr v0.24.0 // indirect
@@ -40,12 +47,11 @@ require (
 	go.uber.org/atomic v1.11.0 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
 	go.uber.org/zap v1.27.0 // indirect
-	golang.org/x/crypto v0.39.0 // indirect
 	golang.org/x/exp v0.0.0-20250620022241-b7579e27df2b // indirect
 	golang.org/x/net v0.41.0 // indirect
 	golang.org/x/sync v0.16.0 // indirect
 	golang.org/x/sys v0.34.0 // indirect
-	golang.org/x/text v0.26.0 // indirect
+	golang.org/x/text v0.28.0 // indirect
 	google.golang.org/genproto/googleapis/api v0.0.0-20250707201910-8d1bb00bc6a7 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20250707201910-8d1bb00bc6a7 // indirect
 	google.golang.org/grpc v1.75.0 // indirect
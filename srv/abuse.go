@@ -0,0 +1,150 @@
+package srv
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"srv.exe.dev/db/dbgen"
+)
+
+// Reasons a click is not counted. They are stored in click_rejections.
+const (
+	rejectBot         = "bot"
+	rejectCrossOrigin = "cross_origin"
+	rejectRateLimited = "rate_limited"
+	rejectDuplicate   = "duplicate"
+	rejectDropped     = "dropped" // lost while the database was unavailable
+)
+
+const (
+	// clickBurst and clickRefill size the per-IP token bucket: a client may
+	// click clickBurst times in a row and then once per clickRefill.
+	clickBurst  = 10
+	clickRefill = 6 * time.Second
+
+	// clickDedupWindow is how long repeat clicks by the same visitor on the
+	// same app are collapsed into one.
+	clickDedupWindow = 30 * time.Minute
+
+	// guardPruneSize is the map size above which idle entries are dropped.
+	guardPruneSize = 10000
+)
+
+// clickGuard holds the in-memory state of the click abuse filters.
+type clickGuard struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	seen    map[string]time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// allow takes a token from ip's bucket. When the bucket is empty it returns
+// false and how long until the next token.
+func (g *clickGuard) allow(ip string, now time.Time) (bool, time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.buckets == nil {
+		g.buckets = make(map[string]*tokenBucket)
+	}
+	if len(g.buckets) > guardPruneSize {
+		for k, b := range g.buckets {
+			if now.Sub(b.last) > clickBurst*clickRefill {
+				delete(g.buckets, k)
+			}
+		}
+	}
+	b, ok := g.buckets[ip]
+	if !ok {
+		b = &tokenBucket{tokens: clickBurst, last: now}
+		g.buckets[ip] = b
+	}
+	b.tokens = math.Min(clickBurst, b.tokens+now.Sub(b.last).Seconds()/clickRefill.Seconds())
+	b.last = now
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) * float64(clickRefill))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// firstSeen records a click by visitor on app and reports whether it is the
+// first one within clickDedupWindow.
+func (g *clickGuard) firstSeen(visitor string, appID int64, now time.Time) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.seen == nil {
+		g.seen = make(map[string]time.Time)
+	}
+	if len(g.seen) > guardPruneSize {
+		for k, t := range g.seen {
+			if now.Sub(t) > clickDedupWindow {
+				delete(g.seen, k)
+			}
+		}
+	}
+	key := visitor + ":" + strconv.FormatInt(appID, 10)
+	if t, ok := g.seen[key]; ok && now.Sub(t) < clickDedupWindow {
+		return false
+	}
+	g.seen[key] = now
+	return true
+}
+
+// screenClick runs the crawler, rate limit and duplicate filters for a
+// click on appID. It returns the rejection reason, or "" if the click
+// should be counted, and for rate-limited clients how long to wait.
+func (s *Server) screenClick(ctx context.Context, r *http.Request, appID int64) (string, time.Duration) {
+	if deviceClass(r.UserAgent()) == "bot" || r.UserAgent() == "" {
+		return rejectBot, 0
+	}
+	now := time.Now()
+	if ok, wait := s.clicks.allow(s.clientIP(r), now); !ok {
+		return rejectRateLimited, wait
+	}
+	visitor, err := s.visitorID(ctx, r, now)
+	if err != nil {
+		slog.Warn("click visitor id", "error", err)
+		return "", 0
+	}
+	if !s.clicks.firstSeen(visitor, appID, now) {
+		return rejectDuplicate, 0
+	}
+	return "", 0
+}
+
+// countRejection records a dropped click so the stats page can show how
+// much is filtered out.
+func (s *Server) countRejection(ctx context.Context, reason string) {
+	if err := dbgen.New(s.DB).CountClickRejection(ctx, reason); err != nil {
+		slog.Warn("count click rejection", "reason", reason, "error", err)
+	}
+}
+
+// sameOrigin reports whether r was sent by a page on this site, judged by
+// the Origin header or, failing that, the Referer.
+func sameOrigin(r *http.Request) bool {
+	src := r.Header.Get("Origin")
+	if src == "" || src == "null" {
+		src = r.Referer()
+	}
+	if src == "" {
+		return false
+	}
+	u, err := url.Parse(src)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
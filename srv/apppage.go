@@ -0,0 +1,105 @@
+package srv
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"srv.exe.dev/db/dbgen"
+)
+
+// uniqueSlug returns a slug for app id that no other app uses: want if
+// given, else one generated from title, with "-2", "-3", ... appended on
+// collisions. Pass id 0 for an app that doesn't exist yet.
+func uniqueSlug(ctx context.Context, q *dbgen.Queries, want, title string, id int64) (string, error) {
+	base := slugify(want)
+	if base == "" {
+		base = slugify(title)
+	}
+	if base == "" {
+		base = "app"
+	}
+	for n := 1; ; n++ {
+		slug := base
+		if n > 1 {
+			slug = fmt.Sprintf("%s-%d", base, n)
+		}
+		other, err := q.GetAppBySlug(ctx, slug)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && other.ID == id) {
+			return slug, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// fillSlugs gives every app without a slug one generated from its title.
+func (s *Server) fillSlugs(ctx context.Context) error {
+	q := dbgen.New(s.DB)
+	apps, err := q.ListAppsWithoutSlug(ctx)
+	if err != nil {
+		return err
+	}
+	for _, app := range apps {
+		slug, err := uniqueSlug(ctx, q, "", app.Title, app.ID)
+		if err != nil {
+			return err
+		}
+		if err := q.SetAppSlug(ctx, dbgen.SetAppSlugParams{Slug: slug, ID: app.ID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type appPageData struct {
+	Hostname string
+	App      appEntry
+	Authors  []citeName
+	JSONLD   webApplication
+}
+
+// HandleApp shows the detail page of one app.
+func (s *Server) HandleApp(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	q := dbgen.New(s.DB)
+	app, err := q.GetAppBySlug(ctx, r.PathValue("slug"))
+	if errors.Is(err, sql.ErrNoRows) {
+		s.notFound(w, r)
+		return
+	}
+	if err != nil {
+		slog.Warn("get app", "slug", r.PathValue("slug"), "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	entry := appEntry{App: app}
+	if stats, err := q.ListClickStats(ctx); err != nil {
+		slog.Warn("click stats", "error", err)
+	} else {
+		for _, st := range stats {
+			if st.AppID == app.ID {
+				entry.Clicks7d, entry.Clicks30d, entry.ClicksTotal = st.Clicks7d, st.Clicks30d, st.ClicksTotal
+			}
+		}
+	}
+	if entry.Tags, err = q.ListTagsForApp(ctx, app.ID); err != nil {
+		slog.Warn("list app tags", "error", err)
+	}
+	if entry.SDGs, err = q.ListSDGsForApp(ctx, app.ID); err != nil {
+		slog.Warn("list app sdgs", "error", err)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	ld := appJSONLD(s.baseURL(r), entry)
+	ld.Context = "https://schema.org"
+	data := appPageData{Hostname: s.Hostname, App: entry, Authors: citeAuthors(app), JSONLD: ld}
+	if err := s.renderTemplate(w, r, "app.html", data); err != nil {
+		slog.Warn("render template", "url", r.URL.Path, "error", err)
+	}
+}
@@ -0,0 +1,326 @@
+package srv
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"srv.exe.dev/db/dbgen"
+)
+
+const (
+	// sessionCookie holds the admin session token.
+	sessionCookie = "session"
+
+	// sessionTTL is how long a login lasts.
+	sessionTTL = 24 * time.Hour
+
+	// minPasswordLength is the shortest admin password accepted. bcrypt
+	// ignores everything after 72 bytes, so longer ones are refused too.
+	minPasswordLength = 10
+	maxPasswordLength = 72
+)
+
+// dummyHash is compared against when a login names an unknown user, so
+// the response takes as long as for a wrong password.
+var dummyHash = sync.OnceValue(func() []byte {
+	h, _ := bcrypt.GenerateFromPassword([]byte("not a password"), bcrypt.DefaultCost)
+	return h
+})
+
+// AddAdminUser creates an owner account with a bcrypt hash of password.
+func AddAdminUser(ctx context.Context, database *sql.DB, username, password string) error {
+	if err := checkPassword(password); err != nil {
+		return err
+	}
+	_, err := createAdminUser(ctx, dbgen.New(database), username, password, roleOwner)
+	return err
+}
+
+// checkPassword enforces the password length limits.
+func checkPassword(password string) error {
+	if len(password) < minPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+	if len(password) > maxPasswordLength {
+		return fmt.Errorf("password must be at most %d bytes", maxPasswordLength)
+	}
+	return nil
+}
+
+func createAdminUser(ctx context.Context, q *dbgen.Queries, username, password string, rl role) (dbgen.AdminUser, error) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return dbgen.AdminUser{}, errors.New("username must not be empty")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return dbgen.AdminUser{}, err
+	}
+	user, err := q.CreateAdminUser(ctx, dbgen.CreateAdminUserParams{
+		Username:     username,
+		PasswordHash: string(hash),
+		Role:         string(rl),
+	})
+	if err != nil {
+		return dbgen.AdminUser{}, fmt.Errorf("create admin user %q: %w", username, err)
+	}
+	return user, nil
+}
+
+// EnsureAdmin makes sure someone can log in to the admin, with an account
+// or through exe.dev. If neither is possible, the owner "admin" is created
+// with $ADMIN_PASSWORD, or in dev mode with the password "changeme".
+// Otherwise the server must not start.
+func (s *Server) EnsureAdmin(ctx context.Context) error {
+	n, err := dbgen.New(s.DB).CountAdminUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("count admin users: %w", err)
+	}
+	if n > 0 || s.ExeDev.Enabled() {
+		return nil
+	}
+	if password := os.Getenv("ADMIN_PASSWORD"); password != "" {
+		slog.Info("creating admin user from ADMIN_PASSWORD", "username", "admin")
+		if err := AddAdminUser(ctx, s.DB, "admin", password); err != nil {
+			return fmt.Errorf("ADMIN_PASSWORD: %w", err)
+		}
+		return nil
+	}
+	if s.Dev {
+		slog.Warn("dev mode: creating admin user with the default password", "username", "admin", "password", "changeme")
+		_, err := createAdminUser(ctx, dbgen.New(s.DB), "admin", "changeme", roleOwner)
+		return err
+	}
+	return errors.New("no admin user: create one with \"srv adduser NAME\" or set ADMIN_PASSWORD")
+}
+
+// sessionID is the database key of a session token.
+func sessionID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// admin is a logged-in admin, from a session or the exe.dev proxy.
+type admin struct {
+	dbgen.AdminUser
+	Session  string // session ID, unless ExeDev
+	ExeDev   bool   // signed in through exe.dev rather than a session
+	ExeDevID string // X-ExeDev-UserID, if ExeDev
+}
+
+// currentAdmin returns the admin whose session cookie r carries or, failing
+// that, whom trusted exe.dev identity headers name.
+func (s *Server) currentAdmin(r *http.Request) (admin, bool) {
+	c, err := r.Cookie(sessionCookie)
+	if err != nil || c.Value == "" {
+		return s.exeDevAdmin(r)
+	}
+	id := sessionID(c.Value)
+	user, err := dbgen.New(s.DB).GetSessionUser(r.Context(), dbgen.GetSessionUserParams{
+		ID:  id,
+		Now: time.Now().UTC(),
+	})
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			slog.Warn("get session", "error", err)
+		}
+		return s.exeDevAdmin(r)
+	}
+	return admin{AdminUser: user, Session: id}, true
+}
+
+// requireRole returns the logged-in admin if they have at least role need.
+// Otherwise page loads by anonymous users are sent to the login form,
+// other anonymous requests get a 401 and admins lacking the role a 403.
+func (s *Server) requireRole(w http.ResponseWriter, r *http.Request, need role) (admin, bool) {
+	user, ok := s.currentAdmin(r)
+	if ok && role(user.Role).atLeast(need) {
+		return user, true
+	}
+	switch {
+	case ok:
+		http.Error(w, "Forbidden: this needs the "+string(need)+" role", http.StatusForbidden)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		http.Redirect(w, r, "/admin/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+	default:
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	}
+	return admin{}, false
+}
+
+// setSessionCookie sets or, with an empty token, clears the session cookie.
+// Outside dev mode it is only sent over HTTPS.
+func (s *Server) setSessionCookie(w http.ResponseWriter, token string, expires time.Time) {
+	c := &http.Cookie{
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   !s.Dev,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if token == "" {
+		c.MaxAge = -1
+	}
+	http.SetCookie(w, c)
+}
+
+// safeNext returns next if it is a path on this site, else "/admin".
+func safeNext(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/admin"
+	}
+	return next
+}
+
+type loginPageData struct {
+	Next     string
+	Username string
+	Error    string
+	ExeDev   string // exe.dev login URL, if exe.dev logins are on
+}
+
+// HandleLoginPage shows the admin login form.
+func (s *Server) HandleLoginPage(w http.ResponseWriter, r *http.Request) {
+	next := safeNext(r.URL.Query().Get("next"))
+	if _, ok := s.currentAdmin(r); ok {
+		http.Redirect(w, r, next, http.StatusSeeOther)
+		return
+	}
+	s.renderLogin(w, r, http.StatusOK, loginPageData{Next: next})
+}
+
+func (s *Server) renderLogin(w http.ResponseWriter, r *http.Request, status int, data loginPageData) {
+	if s.ExeDev.Enabled() {
+		data.ExeDev = exeDevLoginURL(data.Next)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := s.renderTemplate(w, r, "login.html", data); err != nil {
+		slog.Warn("render template", "url", r.URL.Path, "error", err)
+	}
+}
+
+// HandleLogin checks the submitted credentials and starts a session.
+// Every attempt is counted before the password is looked at, and clients
+// and usernames with too many failures are turned away until their
+// lockout ends.
+func (s *Server) HandleLogin(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	username := strings.TrimSpace(r.FormValue("username"))
+	password := r.FormValue("password")
+	next := safeNext(r.FormValue("next"))
+
+	now := time.Now().UTC().Truncate(time.Second)
+	ip := s.clientIP(r)
+	keys := loginThrottleKeys(ip, username)
+	locked, backoff, err := s.countLoginAttempt(ctx, keys, now)
+	if err != nil {
+		slog.Warn("count login attempt", "error", err)
+		setRetryAfter(w, time.Second)
+		s.renderLogin(w, r, http.StatusServiceUnavailable, loginPageData{
+			Next:     next,
+			Username: username,
+			Error:    "The login is busy. Please try again in a moment.",
+		})
+		return
+	}
+	if locked > 0 {
+		slog.Info("admin login refused while locked out", "username", username, "ip", ip)
+		s.auditLoginFailure(ctx, ip, username, loginFailedLocked, now)
+		setRetryAfter(w, locked)
+		s.renderLogin(w, r, http.StatusTooManyRequests, loginPageData{
+			Next:     next,
+			Username: username,
+			Error:    lockoutMessage(locked),
+		})
+		return
+	}
+
+	q := dbgen.New(s.DB)
+	user, err := q.GetAdminUserByName(ctx, username)
+	hash := []byte(user.PasswordHash)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			slog.Warn("get admin user", "error", err)
+		}
+		hash = dummyHash()
+	}
+	if bcrypt.CompareHashAndPassword(hash, []byte(password)) != nil || err != nil {
+		slog.Info("admin login failed", "username", username, "ip", ip)
+		s.auditLoginFailure(ctx, ip, username, loginFailedPassword, now)
+		data := loginPageData{
+			Next:     next,
+			Username: username,
+			Error:    "Wrong username or password.",
+		}
+		if backoff > 0 {
+			slog.Warn("admin login locked out", "username", username, "ip", ip, "for", backoff)
+			setRetryAfter(w, backoff)
+			data.Error += " " + lockoutMessage(backoff)
+		}
+		s.renderLogin(w, r, http.StatusUnauthorized, data)
+		return
+	}
+	s.clearLoginThrottles(ctx, keys)
+
+	if err := s.startSession(w, r, user); err != nil {
+		slog.Warn("start session", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	slog.Info("admin login", "username", user.Username)
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+// startSession logs user in: it stores a new session and sets its cookie.
+func (s *Server) startSession(w http.ResponseWriter, r *http.Request, user dbgen.AdminUser) error {
+	ctx := r.Context()
+	q := dbgen.New(s.DB)
+	token := rand.Text()
+	now := time.Now().UTC().Truncate(time.Second)
+	expires := now.Add(sessionTTL)
+	err := q.CreateSession(ctx, dbgen.CreateSessionParams{
+		ID:        sessionID(token),
+		UserID:    user.ID,
+		CreatedAt: now,
+		ExpiresAt: expires,
+	})
+	if err != nil {
+		return err
+	}
+	if err := q.SetAdminLastLogin(ctx, dbgen.SetAdminLastLoginParams{ID: user.ID, LastLoginAt: &now}); err != nil {
+		slog.Warn("set last login", "error", err)
+	}
+	if err := q.DeleteExpiredSessions(ctx, now); err != nil {
+		slog.Warn("delete expired sessions", "error", err)
+	}
+	s.setSessionCookie(w, token, expires)
+	return nil
+}
+
+// HandleLogout ends the current session.
+func (s *Server) HandleLogout(w http.ResponseWriter, r *http.Request) {
+	if c, err := r.Cookie(sessionCookie); err == nil && c.Value != "" {
+		if err := dbgen.New(s.DB).DeleteSession(r.Context(), sessionID(c.Value)); err != nil {
+			slog.Warn("delete session", "error", err)
+		}
+	}
+	s.setSessionCookie(w, "", time.Unix(0, 0))
+	http.Redirect(w, r, "/admin/login", http.StatusSeeOther)
+}
@@ -0,0 +1,25 @@
+package srv
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestEnsureAdminShortPassword(t *testing.T) {
+	s := newTestServer(t)
+	t.Setenv("ADMIN_PASSWORD", "short")
+	err := s.EnsureAdmin(context.Background())
+	if err == nil || !strings.HasPrefix(err.Error(), "ADMIN_PASSWORD: ") {
+		t.Fatalf("EnsureAdmin with a short ADMIN_PASSWORD = %v, want an ADMIN_PASSWORD error", err)
+	}
+
+	t.Setenv("ADMIN_PASSWORD", "long enough now")
+	if err := s.EnsureAdmin(context.Background()); err != nil {
+		t.Fatalf("EnsureAdmin: %v", err)
+	}
+	t.Setenv("ADMIN_PASSWORD", "short")
+	if err := s.EnsureAdmin(context.Background()); err != nil {
+		t.Errorf("EnsureAdmin with an admin in place: %v", err)
+	}
+}
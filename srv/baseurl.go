@@ -0,0 +1,74 @@
+package srv
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ParseBaseURL checks a configured base URL such as
+// "https://kohlschwarz.at:8000" and returns it without a trailing slash.
+func ParseBaseURL(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("base URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("base URL %q: want http(s)://host[:port]", raw)
+	}
+	if u.Path != "" && u.Path != "/" || u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("base URL %q: must not have a path, query or fragment", raw)
+	}
+	return u.Scheme + "://" + u.Host, nil
+}
+
+// baseURL returns the origin absolute URLs are built from: BaseURL if
+// configured, else the scheme and host the request came in on. Like the
+// other forwarding headers, X-Forwarded-Proto is only believed from a
+// trusted proxy, as the URLs end up in cached pages and images.
+func (s *Server) baseURL(r *http.Request) string {
+	if s.BaseURL != "" {
+		return s.BaseURL
+	}
+	scheme := "http"
+	if r.TLS != nil || s.ExeDev.fromTrustedProxy(r) && r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// absURL makes a site-relative path such as a thumbnail absolute. URLs that
+// already carry a scheme are returned as is.
+func absURL(base, path string) string {
+	if path == "" || strings.Contains(path, "://") {
+		return path
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return base + path
+}
+
+// canonicalHost redirects requests for any other host than the one in
+// BaseURL there with a 301, so every page has one address. Without a
+// configured BaseURL all hosts are served.
+func (s *Server) canonicalHost(next http.Handler) http.Handler {
+	if s.BaseURL == "" {
+		return next
+	}
+	u, err := url.Parse(s.BaseURL)
+	if err != nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.EqualFold(r.Host, u.Host) {
+			http.Redirect(w, r, s.BaseURL+r.URL.RequestURI(), http.StatusMovedPermanently)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
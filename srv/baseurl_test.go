@@ -0,0 +1,38 @@
+package srv
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBaseURLForwardedProto(t *testing.T) {
+	s := newTestServer(t)
+	var err error
+	s.ExeDev, err = NewExeDevAuth([]string{"10.0.0.0/8"}, nil)
+	if err != nil {
+		t.Fatalf("NewExeDevAuth: %v", err)
+	}
+	tests := []struct {
+		remote, proto, want string
+	}{
+		{"203.0.113.5:1234", "", "http://example.com"},
+		{"203.0.113.5:1234", "https", "http://example.com"},
+		{"10.1.2.3:1234", "https", "https://example.com"},
+		{"10.1.2.3:1234", "", "http://example.com"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = tt.remote
+		if tt.proto != "" {
+			r.Header.Set("X-Forwarded-Proto", tt.proto)
+		}
+		if got := s.baseURL(r); got != tt.want {
+			t.Errorf("baseURL from %s with X-Forwarded-Proto %q = %q, want %q", tt.remote, tt.proto, got, tt.want)
+		}
+	}
+
+	s.BaseURL = "https://kohlschwarz.at"
+	if got := s.baseURL(httptest.NewRequest("GET", "/", nil)); got != s.BaseURL {
+		t.Errorf("configured baseURL = %q, want %q", got, s.BaseURL)
+	}
+}
@@ -0,0 +1,276 @@
+package srv
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/goregular"
+	"rsc.io/qr"
+
+	"srv.exe.dev/db/dbgen"
+)
+
+const (
+	// catalogSetting is the settings key that makes /catalog.pdf public
+	// when set to "1"; otherwise only admins may download it.
+	catalogSetting = "catalog_public"
+
+	// Page layout of the catalog, in millimetres (A4 portrait).
+	catalogPageWidth  = 210.0
+	catalogPageHeight = 297.0
+	catalogMargin     = 20.0
+	catalogQRSize     = 40.0
+)
+
+// catalogCache serialises catalog generation, as ogCache does for images.
+type catalogCache struct {
+	mu sync.Mutex
+}
+
+// catalogPublic reports whether the admin made /catalog.pdf public.
+func (s *Server) catalogPublic(ctx context.Context) bool {
+	v, err := dbgen.New(s.DB).GetSetting(ctx, catalogSetting)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		slog.Warn("get catalog setting", "error", err)
+	}
+	return v == "1"
+}
+
+// HandleCatalog serves /catalog.pdf, a brochure of all apps. The PDF is
+// generated once and kept in CacheDir until an app is added, changed or
+// deleted.
+func (s *Server) HandleCatalog(w http.ResponseWriter, r *http.Request) {
+	if !s.catalogPublic(r.Context()) {
+		// Only admins may see it, so no shared cache may keep a copy.
+		w.Header().Set("Cache-Control", "private, no-store")
+		if _, ok := s.requireRole(w, r, roleViewer); !ok {
+			return
+		}
+	}
+	apps, err := dbgen.New(s.DB).ListApps(r.Context())
+	if err != nil {
+		slog.Warn("list apps", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	path, err := s.catalogFile(s.baseURL(r), apps)
+	if err != nil {
+		slog.Warn("catalog", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		slog.Warn("open catalog", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		slog.Warn("stat catalog", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `inline; filename="kohlschwarz-katalog.pdf"`)
+	http.ServeContent(w, r, "", fi.ModTime(), f)
+}
+
+// catalogFile returns the path of the cached catalog for apps, rendering
+// it first if needed. The file name is a hash of every app's ID and
+// updated_at, plus base, which the QR codes point at.
+func (s *Server) catalogFile(base string, apps []dbgen.App) (string, error) {
+	h := sha256.New()
+	fmt.Fprintln(h, base)
+	for _, app := range apps {
+		fmt.Fprintln(h, app.ID, app.UpdatedAt.UnixNano())
+	}
+	key := hex.EncodeToString(h.Sum(nil))[:16]
+	path := filepath.Join(s.CacheDir, "catalog-"+key+".pdf")
+
+	s.catalog.mu.Lock()
+	defer s.catalog.mu.Unlock()
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	if err := os.MkdirAll(s.CacheDir, 0o755); err != nil {
+		return "", err
+	}
+	old, _ := filepath.Glob(filepath.Join(s.CacheDir, "catalog-*.pdf"))
+	for _, f := range old {
+		os.Remove(f)
+	}
+
+	var buf bytes.Buffer
+	if err := s.renderCatalog(&buf, base, apps, time.Now()); err != nil {
+		return "", err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return "", err
+	}
+	return path, os.Rename(tmp, path)
+}
+
+// renderCatalog writes the catalog PDF: a cover page, then one page per app
+// with thumbnail, description, prompt, URL and QR code.
+func (s *Server) renderCatalog(w io.Writer, base string, apps []dbgen.App, generated time.Time) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle("Kohlschwarz Think-Tank – Katalog", true)
+	pdf.SetAuthor(feedAuthor, true)
+	pdf.SetCreationDate(generated)
+	pdf.AddUTF8FontFromBytes("go", "", goregular.TTF)
+	pdf.AddUTF8FontFromBytes("go", "B", gobold.TTF)
+	pdf.AddUTF8FontFromBytes("go", "I", goitalic.TTF)
+	pdf.SetMargins(catalogMargin, catalogMargin, catalogMargin)
+
+	date := generated.Format("02.01.2006")
+	pdf.SetFooterFunc(func() {
+		if pdf.PageNo() == 1 {
+			return
+		}
+		pdf.SetY(-12)
+		pdf.SetFont("go", "", 8)
+		pdf.SetTextColor(0x99, 0x99, 0x99)
+		pdf.CellFormat(0, 4, "Kohlschwarz Think-Tank · Katalog, Stand "+date, "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 4, strconv.Itoa(pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+
+	// Cover page.
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.AddPage()
+	pdf.SetFillColor(0x11, 0x11, 0x11)
+	pdf.Polygon([]fpdf.PointType{
+		{X: catalogMargin + 12, Y: 70},
+		{X: catalogMargin + 24, Y: 94},
+		{X: catalogMargin, Y: 94},
+	}, "F")
+	pdf.SetXY(catalogMargin, 110)
+	pdf.SetTextColor(0x11, 0x11, 0x11)
+	pdf.SetFont("go", "B", 32)
+	pdf.MultiCell(0, 14, "Kohlschwarz Think-Tank", "", "L", false)
+	pdf.SetFont("go", "", 16)
+	pdf.SetTextColor(0x55, 0x55, 0x55)
+	pdf.MultiCell(0, 9, feedDescription, "", "L", false)
+	pdf.Ln(6)
+	pdf.SetFont("go", "", 11)
+	pdf.MultiCell(0, 6, fmt.Sprintf("Katalog mit %d Anwendungen", len(apps)), "", "L", false)
+	pdf.SetY(catalogPageHeight - catalogMargin - 12)
+	pdf.SetFont("go", "", 10)
+	pdf.CellFormat(0, 6, base, "", 1, "L", false, 0, base+"/")
+	pdf.CellFormat(0, 6, "Stand: "+date, "", 1, "L", false, 0, "")
+
+	// One page per app. Text breaks to a new page above the QR code block.
+	for _, app := range apps {
+		pdf.SetAutoPageBreak(true, catalogMargin+catalogQRSize+8)
+		pdf.AddPage()
+		s.catalogAppPage(pdf, base, app)
+	}
+
+	if err := pdf.Error(); err != nil {
+		return err
+	}
+	return pdf.Output(w)
+}
+
+// catalogAppPage draws app on the current page.
+func (s *Server) catalogAppPage(pdf *fpdf.Fpdf, base string, app dbgen.App) {
+	contentWidth := catalogPageWidth - 2*catalogMargin
+	qrTop := catalogPageHeight - catalogMargin - catalogQRSize
+
+	// The QR code goes in first, at the bottom, so it stays on the app's
+	// first page however long the prompt is.
+	if code, err := qr.Encode(scanURL(base, app.ID), qr.M); err != nil {
+		slog.Warn("encode qr", "id", app.ID, "error", err)
+	} else {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, qrImage(code, 4)); err == nil {
+			name := fmt.Sprintf("qr-%d", app.ID)
+			pdf.RegisterImageOptionsReader(name, fpdf.ImageOptions{ImageType: "PNG"}, &buf)
+			pdf.ImageOptions(name, catalogMargin, qrTop, catalogQRSize, catalogQRSize, false, fpdf.ImageOptions{}, 0, "")
+		}
+	}
+	pdf.SetXY(catalogMargin+catalogQRSize+6, qrTop+12)
+	pdf.SetFont("go", "B", 10)
+	pdf.SetTextColor(0x11, 0x11, 0x11)
+	pdf.CellFormat(0, 6, "Scannen, um die Anwendung zu öffnen", "", 2, "L", false, 0, "")
+	pdf.SetFont("go", "", 9)
+	pdf.SetTextColor(0x55, 0x55, 0x55)
+	pdf.CellFormat(0, 5, app.Url, "", 2, "L", false, 0, app.Url)
+	pdf.CellFormat(0, 5, base+"/apps/"+app.Slug, "", 2, "L", false, 0, base+"/apps/"+app.Slug)
+
+	pdf.SetXY(catalogMargin, catalogMargin)
+	pdf.SetFont("go", "B", 22)
+	pdf.SetTextColor(0x11, 0x11, 0x11)
+	pdf.MultiCell(0, 10, app.Title, "", "L", false)
+	pdf.Ln(4)
+
+	if thumb := s.loadThumbnail(app); thumb != nil {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 85}); err == nil {
+			name := fmt.Sprintf("thumb-%d", app.ID)
+			info := pdf.RegisterImageOptionsReader(name, fpdf.ImageOptions{ImageType: "JPG"}, &buf)
+			if info != nil && info.Width() > 0 {
+				w := contentWidth
+				h := w * info.Height() / info.Width()
+				if maxH := 90.0; h > maxH {
+					h, w = maxH, maxH*info.Width()/info.Height()
+				}
+				pdf.ImageOptions(name, catalogMargin, pdf.GetY(), w, h, true, fpdf.ImageOptions{}, 0, "")
+				pdf.Ln(6)
+			}
+		}
+	}
+
+	pdf.SetFont("go", "", 11)
+	pdf.SetTextColor(0x33, 0x33, 0x33)
+	pdf.MultiCell(0, 6, app.Description, "", "L", false)
+
+	if app.Prompt != nil && *app.Prompt != "" {
+		pdf.Ln(6)
+		pdf.SetFont("go", "B", 9)
+		pdf.SetTextColor(0x88, 0x88, 0x88)
+		pdf.CellFormat(0, 6, "PROMPT", "", 1, "L", false, 0, "")
+		pdf.SetFont("go", "I", 9)
+		pdf.SetTextColor(0x55, 0x55, 0x55)
+		pdf.MultiCell(0, 5, *app.Prompt, "", "L", false)
+	}
+}
+
+// HandleAdminCatalog saves whether /catalog.pdf is public.
+func (s *Server) HandleAdminCatalog(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.requireRole(w, r, roleOwner); !ok {
+		return
+	}
+
+	value := "0"
+	if r.FormValue("public") == "1" {
+		value = "1"
+	}
+	err := dbgen.New(s.DB).SetSetting(r.Context(), dbgen.SetSettingParams{Key: catalogSetting, Value: value})
+	if err != nil {
+		slog.Warn("set catalog setting", "error", err)
+	}
+
+	http.Redirect(w, r, "/admin", http.StatusSeeOther)
+}
@@ -0,0 +1,89 @@
+package srv
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// Inline SVG charts for the admin pages. They are rendered on the server so
+// the admin area needs no chart library from a third-party CDN.
+
+const (
+	chartWidth  = 800
+	chartHeight = 200
+	chartPad    = 24
+)
+
+// barChart renders one column per label. Hovering a column shows its label
+// and value.
+func barChart(labels []string, values []int64, color string) template.HTML {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="chart" viewBox="0 0 %d %d" width="100%%" role="img">`, chartWidth, chartHeight+chartPad)
+	maxV := maxOf(values)
+	if len(values) > 0 {
+		slot := float64(chartWidth) / float64(len(values))
+		gap := slot * 0.15
+		for i, v := range values {
+			h := float64(v) / float64(maxV) * float64(chartHeight-chartPad)
+			x := float64(i)*slot + gap/2
+			y := float64(chartHeight) - h
+			fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s: %d</title></rect>`,
+				x, y, slot-gap, h, color, template.HTMLEscapeString(labels[i]), v)
+		}
+		fmt.Fprintf(&b, `<text x="0" y="%d" class="chart-label">%s</text>`, chartHeight+chartPad-6, template.HTMLEscapeString(labels[0]))
+		fmt.Fprintf(&b, `<text x="%d" y="%d" class="chart-label" text-anchor="end">%s</text>`, chartWidth, chartHeight+chartPad-6, template.HTMLEscapeString(labels[len(labels)-1]))
+	}
+	fmt.Fprintf(&b, `<line x1="0" y1="%d" x2="%d" y2="%d" class="chart-axis"/>`, chartHeight, chartWidth, chartHeight)
+	fmt.Fprintf(&b, `<text x="0" y="12" class="chart-label">max %d</text>`, maxV)
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// hbarChart renders one horizontal bar per label, longest first as given.
+func hbarChart(labels []string, values []int64, color string) template.HTML {
+	const rowHeight, labelWidth = 28, 160
+	height := len(values)*rowHeight + 4
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="chart" viewBox="0 0 %d %d" width="100%%" role="img">`, chartWidth, height)
+	maxV := maxOf(values)
+	for i, v := range values {
+		y := i*rowHeight + 4
+		w := float64(v) / float64(maxV) * float64(chartWidth-labelWidth-60)
+		fmt.Fprintf(&b, `<text x="0" y="%d" class="chart-label">%s</text>`, y+16, template.HTMLEscapeString(labels[i]))
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%.1f" height="%d" fill="%s"/>`, labelWidth, y+4, w, rowHeight-10, color)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" class="chart-label">%d</text>`, float64(labelWidth)+w+6, y+16, v)
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// sparkline renders values as a small line without axes.
+func sparkline(values []int64, color string) template.HTML {
+	const w, h = 120, 24
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="sparkline" viewBox="0 0 %d %d" width="%d" height="%d" role="img">`, w, h, w, h)
+	if len(values) > 1 {
+		maxV := maxOf(values)
+		step := float64(w) / float64(len(values)-1)
+		points := make([]string, len(values))
+		for i, v := range values {
+			y := float64(h-2) - float64(v)/float64(maxV)*float64(h-4)
+			points[i] = fmt.Sprintf("%.1f,%.1f", float64(i)*step, y)
+		}
+		fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="1.5"/>`, strings.Join(points, " "), color)
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// maxOf returns the largest value, or 1 so it can be used as a divisor.
+func maxOf(values []int64) int64 {
+	m := int64(1)
+	for _, v := range values {
+		if v > m {
+			m = v
+		}
+	}
+	return m
+}
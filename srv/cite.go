@@ -0,0 +1,244 @@
+package srv
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"srv.exe.dev/db/dbgen"
+)
+
+// citeName is one author of an app: a person with family and given name,
+// or an organisation with only Literal set.
+type citeName struct {
+	Family  string `json:"family,omitempty"`
+	Given   string `json:"given,omitempty"`
+	Literal string `json:"literal,omitempty"`
+}
+
+func (n citeName) String() string {
+	if n.Literal != "" {
+		return n.Literal
+	}
+	return strings.TrimSpace(n.Given + " " + n.Family)
+}
+
+// citeAuthors parses the app's authors field, one author per line. Apps
+// without authors are credited to the think tank.
+func citeAuthors(app dbgen.App) []citeName {
+	var names []citeName
+	for _, line := range strings.Split(app.Authors, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if family, given, ok := strings.Cut(line, ","); ok {
+			names = append(names, citeName{Family: strings.TrimSpace(family), Given: strings.TrimSpace(given)})
+		} else {
+			names = append(names, citeName{Literal: line})
+		}
+	}
+	if len(names) == 0 {
+		names = []citeName{{Literal: feedAuthor}}
+	}
+	return names
+}
+
+// normalizeDOI strips resolver prefixes so only the bare DOI ("10.…") is
+// stored.
+func normalizeDOI(doi string) string {
+	doi = strings.TrimSpace(doi)
+	for _, prefix := range []string{"https://doi.org/", "http://doi.org/", "https://dx.doi.org/", "http://dx.doi.org/", "doi:"} {
+		if rest, ok := strings.CutPrefix(strings.ToLower(doi), prefix); ok {
+			return doi[len(doi)-len(rest):]
+		}
+	}
+	return doi
+}
+
+// citeKey is the BibTeX key of an app, e.g. "grundwasser-2025".
+func citeKey(app dbgen.App) string {
+	return fmt.Sprintf("%s-%d", app.Slug, app.CreatedAt.Year())
+}
+
+// citeURL is the address cited for an app: its detail page on this site.
+func citeURL(base string, app dbgen.App) string {
+	return base + "/apps/" + app.Slug
+}
+
+var bibEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`&`, `\&`,
+	`%`, `\%`,
+	`$`, `\$`,
+	`#`, `\#`,
+	`_`, `\_`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+)
+
+// bibTeX formats app as a BibTeX @software entry. Organisations are
+// braced so BibTeX doesn't split them into given and family names.
+func bibTeX(base string, app dbgen.App) string {
+	var authors []string
+	for _, n := range citeAuthors(app) {
+		if n.Literal != "" {
+			authors = append(authors, "{"+bibEscaper.Replace(n.Literal)+"}")
+		} else {
+			authors = append(authors, bibEscaper.Replace(n.Family+", "+n.Given))
+		}
+	}
+
+	var b strings.Builder
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "  %-9s = {%s},\n", name, value)
+		}
+	}
+	fmt.Fprintf(&b, "@software{%s,\n", citeKey(app))
+	field("author", strings.Join(authors, " and "))
+	field("title", "{"+bibEscaper.Replace(app.Title)+"}")
+	field("year", app.CreatedAt.Format("2006"))
+	field("date", app.CreatedAt.Format(time.DateOnly))
+	field("version", bibEscaper.Replace(app.Version))
+	field("doi", app.Doi)
+	field("url", citeURL(base, app))
+	field("publisher", bibEscaper.Replace(feedAuthor))
+	field("note", "Stand: "+app.UpdatedAt.Format(time.DateOnly))
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// cslItem is a CSL-JSON item (https://citeproc-js.readthedocs.io).
+type cslItem struct {
+	ID        string     `json:"id"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Author    []citeName `json:"author"`
+	Issued    cslDate    `json:"issued"`
+	Version   string     `json:"version,omitempty"`
+	DOI       string     `json:"DOI,omitempty"`
+	URL       string     `json:"URL"`
+	Publisher string     `json:"publisher"`
+	Abstract  string     `json:"abstract,omitempty"`
+	Note      string     `json:"note"`
+}
+
+type cslDate struct {
+	DateParts [][]int `json:"date-parts"`
+}
+
+func newCSLDate(t time.Time) cslDate {
+	return cslDate{DateParts: [][]int{{t.Year(), int(t.Month()), t.Day()}}}
+}
+
+func cslJSON(base string, app dbgen.App) cslItem {
+	return cslItem{
+		ID:        citeKey(app),
+		Type:      "software",
+		Title:     app.Title,
+		Author:    citeAuthors(app),
+		Issued:    newCSLDate(app.CreatedAt),
+		Version:   app.Version,
+		DOI:       app.Doi,
+		URL:       citeURL(base, app),
+		Publisher: feedAuthor,
+		Abstract:  app.Description,
+		Note:      "Stand: " + app.UpdatedAt.Format(time.DateOnly),
+	}
+}
+
+// ris formats app as a RIS record of type COMP (computer program).
+func ris(base string, app dbgen.App) string {
+	var b strings.Builder
+	tag := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%s  - %s\r\n", name, strings.ReplaceAll(value, "\n", " "))
+		}
+	}
+	tag("TY", "COMP")
+	tag("ID", citeKey(app))
+	tag("TI", app.Title)
+	for _, n := range citeAuthors(app) {
+		if n.Literal != "" {
+			tag("AU", n.Literal)
+		} else {
+			tag("AU", n.Family+", "+n.Given)
+		}
+	}
+	tag("PY", app.CreatedAt.Format("2006"))
+	tag("DA", app.CreatedAt.Format("2006/01/02"))
+	tag("ET", app.Version)
+	tag("DO", app.Doi)
+	tag("UR", citeURL(base, app))
+	tag("PB", feedAuthor)
+	tag("AB", app.Description)
+	tag("N1", "Stand: "+app.UpdatedAt.Format(time.DateOnly))
+	b.WriteString("ER  - \r\n")
+	return b.String()
+}
+
+// serveCitation sends body as a download named after the app.
+func serveCitation(w http.ResponseWriter, contentType, filename, body string) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Write([]byte(body))
+}
+
+// HandleCiteBib serves /apps/{id}/cite.bib.
+func (s *Server) HandleCiteBib(w http.ResponseWriter, r *http.Request) {
+	app, ok := s.appByIDParam(w, r)
+	if !ok {
+		return
+	}
+	serveCitation(w, "application/x-bibtex; charset=utf-8", app.Slug+".bib", bibTeX(s.baseURL(r), app))
+}
+
+// HandleCiteJSON serves /apps/{id}/cite.json, a CSL-JSON array with one
+// item as citation managers expect.
+func (s *Server) HandleCiteJSON(w http.ResponseWriter, r *http.Request) {
+	app, ok := s.appByIDParam(w, r)
+	if !ok {
+		return
+	}
+	body, err := json.MarshalIndent([]cslItem{cslJSON(s.baseURL(r), app)}, "", "  ")
+	if err != nil {
+		slog.Warn("encode csl json", "id", app.ID, "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	serveCitation(w, "application/vnd.citationstyles.csl+json", app.Slug+".json", string(body)+"\n")
+}
+
+// HandleCiteRIS serves /apps/{id}/cite.ris.
+func (s *Server) HandleCiteRIS(w http.ResponseWriter, r *http.Request) {
+	app, ok := s.appByIDParam(w, r)
+	if !ok {
+		return
+	}
+	serveCitation(w, "application/x-research-info-systems; charset=utf-8", app.Slug+".ris", ris(s.baseURL(r), app))
+}
+
+// HandleCiteAll serves /cite.bib with an entry for every app.
+func (s *Server) HandleCiteAll(w http.ResponseWriter, r *http.Request) {
+	apps, err := dbgen.New(s.DB).ListApps(r.Context())
+	if err != nil {
+		slog.Warn("list apps", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	base := s.baseURL(r)
+	var b strings.Builder
+	for i, app := range apps {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(bibTeX(base, app))
+	}
+	serveCitation(w, "application/x-bibtex; charset=utf-8", "kohlschwarz.bib", b.String())
+}
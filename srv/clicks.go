@@ -0,0 +1,419 @@
+package srv
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"srv.exe.dev/db"
+	"srv.exe.dev/db/dbgen"
+)
+
+const (
+	// clickRollupInterval is how often click events are folded into the
+	// hourly and daily rollup tables.
+	clickRollupInterval = time.Minute
+
+	// DefaultClickFlushInterval is how often queued clicks are written to
+	// the database unless Server.ClickFlushInterval says otherwise.
+	DefaultClickFlushInterval = 5 * time.Second
+
+	// maxPendingClicks caps the click buffer. While the database can't be
+	// written, the oldest clicks are dropped beyond this many, so memory
+	// and the size of the retried transaction stay bounded.
+	maxPendingClicks = 10000
+)
+
+// appEntry is an app together with its click statistics, as shown on the
+// index and admin pages and returned by /api/apps.
+type appEntry struct {
+	dbgen.App
+	Clicks7d    int64       `json:"clicks_7d"`
+	Clicks30d   int64       `json:"clicks_30d"`
+	ClicksTotal int64       `json:"clicks_total"`
+	Tags        []dbgen.Tag `json:"tags"`
+	SDGs        []int64     `json:"sdgs"`
+
+	// Score is the time-decayed click score; set by the popular ranking.
+	Score float64 `json:"score,omitempty"`
+}
+
+// appQuery selects and orders the apps returned by listApps.
+type appQuery struct {
+	Ranking ranking
+
+	// Search is free text typed by a visitor. Only matching apps are
+	// returned, ordered by relevance unless ByRanking is set.
+	Search    string
+	ByRanking bool
+
+	// Tags restricts the result to apps carrying all of these tag slugs,
+	// or any of them if MatchAnyTag is set.
+	Tags        []string
+	MatchAnyTag bool
+
+	// SDG restricts the result to apps linked to this goal, if non-zero.
+	SDG int64
+}
+
+// listApps returns the apps selected by aq, annotated with their clicks
+// over the last 7 days, 30 days and all time.
+func (s *Server) listApps(ctx context.Context, aq appQuery) ([]appEntry, error) {
+	q := dbgen.New(s.DB)
+	apps, err := q.ListApps(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tagRows, err := q.ListAppTags(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("app tags: %w", err)
+	}
+	tagsByApp := make(map[int64][]dbgen.Tag)
+	for _, row := range tagRows {
+		tagsByApp[row.AppID] = append(tagsByApp[row.AppID], dbgen.Tag{ID: row.ID, Slug: row.Slug, Name: row.Name})
+	}
+
+	sdgRows, err := q.ListAppSDGs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("app sdgs: %w", err)
+	}
+	sdgsByApp := make(map[int64][]int64)
+	for _, row := range sdgRows {
+		sdgsByApp[row.AppID] = append(sdgsByApp[row.AppID], row.Goal)
+	}
+
+	var relevance map[int64]int
+	if aq.Search != "" {
+		relevance = make(map[int64]int)
+		if match := searchQuery(aq.Search); match != "" {
+			results, err := db.SearchApps(ctx, s.DB, match)
+			if err != nil {
+				return nil, fmt.Errorf("search: %w", err)
+			}
+			for i, res := range results {
+				relevance[res.AppID] = i
+			}
+		}
+		apps = slices.DeleteFunc(apps, func(app dbgen.App) bool {
+			_, ok := relevance[app.ID]
+			return !ok
+		})
+	}
+	stats, err := q.ListClickStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("click stats: %w", err)
+	}
+	byApp := make(map[int64]dbgen.ListClickStatsRow, len(stats))
+	for _, st := range stats {
+		byApp[st.AppID] = st
+	}
+	entries := make([]appEntry, 0, len(apps))
+	for _, app := range apps {
+		st := byApp[app.ID]
+		entry := appEntry{
+			App:         app,
+			Clicks7d:    st.Clicks7d,
+			Clicks30d:   st.Clicks30d,
+			ClicksTotal: st.ClicksTotal,
+			Tags:        tagsByApp[app.ID],
+			SDGs:        sdgsByApp[app.ID],
+		}
+		if entry.Tags == nil {
+			entry.Tags = []dbgen.Tag{}
+		}
+		if entry.SDGs == nil {
+			entry.SDGs = []int64{}
+		}
+		if len(aq.Tags) > 0 && !hasTags(entry, aq.Tags, aq.MatchAnyTag) {
+			continue
+		}
+		if aq.SDG != 0 && !slices.Contains(entry.SDGs, aq.SDG) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if relevance != nil && !aq.ByRanking {
+		slices.SortStableFunc(entries, func(a, b appEntry) int {
+			return relevance[a.ID] - relevance[b.ID]
+		})
+		return entries, nil
+	}
+	if err := s.rankApps(ctx, entries, aq.Ranking); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// pendingClick is a click waiting in memory for the next flush.
+type pendingClick struct {
+	appID        int64
+	at           time.Time
+	referrer     string
+	referrerHost string
+	device       string
+}
+
+// clickBuffer collects clicks between flushes, so a burst of clicks costs
+// one write transaction instead of one per click.
+type clickBuffer struct {
+	mu      sync.Mutex
+	pending []pendingClick
+	dropped int64 // clicks dropped since the last successful flush
+}
+
+// trim drops the oldest pending clicks beyond maxPendingClicks. The
+// caller holds b.mu. Reslicing is enough: the next append that outgrows
+// the array copies only the clicks kept.
+func (b *clickBuffer) trim() {
+	if n := len(b.pending) - maxPendingClicks; n > 0 {
+		b.pending = b.pending[n:]
+		b.dropped += int64(n)
+	}
+}
+
+// recordClick queues a click on appID. It is written to the database by
+// the next flushClicks.
+func (s *Server) recordClick(r *http.Request, appID int64) {
+	c := pendingClick{
+		appID:        appID,
+		at:           time.Now().UTC(),
+		referrer:     referrerClass(r),
+		referrerHost: referrerHost(r),
+		device:       deviceClass(r.UserAgent()),
+	}
+	s.buffer.mu.Lock()
+	s.buffer.pending = append(s.buffer.pending, c)
+	s.buffer.trim()
+	s.buffer.mu.Unlock()
+}
+
+// flushClicks writes all queued clicks in one transaction: an event per
+// click and one click_count update per app. On failure the clicks are put
+// back and retried with the next flush, up to maxPendingClicks; clicks
+// dropped beyond that are counted as a rejection once writing works again.
+func (s *Server) flushClicks(ctx context.Context) error {
+	s.buffer.mu.Lock()
+	batch, dropped := s.buffer.pending, s.buffer.dropped
+	s.buffer.pending, s.buffer.dropped = nil, 0
+	s.buffer.mu.Unlock()
+	if len(batch) == 0 && dropped == 0 {
+		return nil
+	}
+
+	if err := s.writeClicks(ctx, batch, dropped); err != nil {
+		s.buffer.mu.Lock()
+		s.buffer.pending = append(batch, s.buffer.pending...)
+		s.buffer.dropped += dropped
+		s.buffer.trim()
+		dropped = s.buffer.dropped
+		s.buffer.mu.Unlock()
+		if dropped > 0 {
+			slog.Warn("click buffer full, dropping the oldest clicks", "dropped", dropped, "kept", maxPendingClicks)
+		}
+		return err
+	}
+	if dropped > 0 {
+		slog.Warn("clicks were dropped while the database was unavailable", "dropped", dropped)
+	}
+	return nil
+}
+
+func (s *Server) writeClicks(ctx context.Context, batch []pendingClick, dropped int64) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := dbgen.New(tx)
+	perApp := make(map[int64]int64)
+	for _, c := range batch {
+		err := q.InsertClickEvent(ctx, dbgen.InsertClickEventParams{
+			AppID:         c.appID,
+			ClickedAt:     c.at.Format(time.DateTime),
+			ReferrerClass: c.referrer,
+			ReferrerHost:  c.referrerHost,
+			DeviceClass:   c.device,
+		})
+		if err != nil {
+			return fmt.Errorf("insert click event: %w", err)
+		}
+		perApp[c.appID]++
+	}
+	for id, n := range perApp {
+		if err := q.AddClickCount(ctx, dbgen.AddClickCountParams{ID: id, Clicks: n}); err != nil {
+			return fmt.Errorf("add click count: %w", err)
+		}
+	}
+	if dropped > 0 {
+		if err := q.AddClickRejections(ctx, dbgen.AddClickRejectionsParams{Reason: rejectDropped, Count: dropped}); err != nil {
+			return fmt.Errorf("count dropped clicks: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// runClickFlusher calls flushClicks every interval. When ctx is done it
+// flushes one last time so no queued clicks are lost on shutdown.
+func (s *Server) runClickFlusher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := s.flushClicks(context.Background()); err != nil {
+				slog.Error("final click flush", "error", err)
+			}
+			return
+		case <-ticker.C:
+			if err := s.flushClicks(ctx); err != nil {
+				slog.Warn("flush clicks", "error", err)
+			}
+		}
+	}
+}
+
+// rollupClicks folds all click events newer than the stored watermark into
+// the hourly and daily rollup tables.
+func (s *Server) rollupClicks(ctx context.Context) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := dbgen.New(tx)
+	last, err := q.GetClickRollupState(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("read rollup state: %w", err)
+	}
+	upTo, err := q.MaxClickEventID(ctx)
+	if err != nil {
+		return fmt.Errorf("max click event: %w", err)
+	}
+	if upTo <= last {
+		return nil
+	}
+	if err := q.RollupClicksHourly(ctx, dbgen.RollupClicksHourlyParams{AfterID: last, UpToID: upTo}); err != nil {
+		return fmt.Errorf("hourly rollup: %w", err)
+	}
+	if err := q.RollupClicksDaily(ctx, dbgen.RollupClicksDailyParams{AfterID: last, UpToID: upTo}); err != nil {
+		return fmt.Errorf("daily rollup: %w", err)
+	}
+	if err := q.SetClickRollupState(ctx, upTo); err != nil {
+		return fmt.Errorf("write rollup state: %w", err)
+	}
+	return tx.Commit()
+}
+
+// runClickRollups calls rollupClicks every interval until ctx is done.
+func (s *Server) runClickRollups(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.rollupClicks(ctx); err != nil {
+			slog.Warn("rollup clicks", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+var searchHosts = []string{"google.", "bing.", "duckduckgo.", "ecosia.", "qwant.", "startpage.", "yahoo.", "yandex.", "search.brave."}
+
+var socialHosts = []string{"t.co", "twitter.", "x.com", "facebook.", "fb.", "instagram.", "linkedin.", "lnkd.in", "reddit.", "mastodon", "bsky.", "threads.", "whatsapp.", "t.me", "telegram.", "news.ycombinator."}
+
+// referrer is the page a visitor came from. An explicit ?ref= (the page's
+// own referrer, passed on by a beacon) wins over the Referer header.
+func referrer(r *http.Request) string {
+	if ref := r.URL.Query().Get("ref"); ref != "" {
+		return ref
+	}
+	return r.Referer()
+}
+
+// referrerHost is the lower-case host of the referrer, or "" for direct
+// visits, links within the site and referrers that aren't URLs.
+func referrerHost(r *http.Request) string {
+	u, err := url.Parse(referrer(r))
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == strings.ToLower(hostOnly(r.Host)) {
+		return ""
+	}
+	return host
+}
+
+// referrerClass reduces where a visitor came from to a coarse class:
+// direct, internal, search, social or other.
+func referrerClass(r *http.Request) string {
+	ref := referrer(r)
+	if ref == "" {
+		return "direct"
+	}
+	u, err := url.Parse(ref)
+	if err != nil || u.Host == "" {
+		return "other"
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == strings.ToLower(hostOnly(r.Host)) {
+		return "internal"
+	}
+	for _, h := range searchHosts {
+		if strings.Contains(host, h) {
+			return "search"
+		}
+	}
+	for _, h := range socialHosts {
+		if host == h || strings.HasPrefix(host, h) || strings.Contains(host, "."+h) {
+			return "social"
+		}
+	}
+	return "other"
+}
+
+var botMarkers = []string{"bot", "crawl", "spider", "slurp", "curl", "wget", "python-requests", "go-http-client", "headless", "preview"}
+
+// deviceClass buckets a User-Agent into bot, tablet, mobile, desktop or
+// unknown.
+func deviceClass(ua string) string {
+	if ua == "" {
+		return "unknown"
+	}
+	l := strings.ToLower(ua)
+	for _, m := range botMarkers {
+		if strings.Contains(l, m) {
+			return "bot"
+		}
+	}
+	switch {
+	case strings.Contains(l, "ipad") || strings.Contains(l, "tablet") ||
+		(strings.Contains(l, "android") && !strings.Contains(l, "mobile")):
+		return "tablet"
+	case strings.Contains(l, "mobi") || strings.Contains(l, "iphone") || strings.Contains(l, "android"):
+		return "mobile"
+	}
+	return "desktop"
+}
+
+// hostOnly strips an optional port from a Host header value.
+func hostOnly(hostport string) string {
+	if i := strings.LastIndexByte(hostport, ':'); i >= 0 && !strings.Contains(hostport[i:], "]") {
+		return hostport[:i]
+	}
+	return hostport
+}
@@ -0,0 +1,128 @@
+package srv
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+const browserUA = "Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0"
+
+// BenchmarkHandleClick measures the request path of a counted click: the
+// abuse filters and queueing it in the buffer. Every iteration comes from
+// another address so none is rate limited or deduplicated.
+func BenchmarkHandleClick(b *testing.B) {
+	s := newTestServer(b)
+	id := strconv.FormatInt(firstApp(b, s).ID, 10)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r := httptest.NewRequest(http.MethodPost, "/api/click/"+id, nil)
+		r.SetPathValue("id", id)
+		r.RemoteAddr = fmt.Sprintf("10.%d.%d.%d:4321", i>>16&0xff, i>>8&0xff, i&0xff)
+		r.Header.Set("Origin", "http://"+r.Host)
+		r.Header.Set("User-Agent", browserUA)
+		w := httptest.NewRecorder()
+		s.HandleTrackClick(w, r)
+		if w.Code != http.StatusOK {
+			b.Fatalf("click %d: status %d: %s", i, w.Code, w.Body)
+		}
+	}
+	b.StopTimer()
+	if err := s.flushClicks(context.Background()); err != nil {
+		b.Fatalf("flush: %v", err)
+	}
+}
+
+// BenchmarkFlushClicks measures writing buffered clicks to the database,
+// in batches of the given size. The reported time is per click.
+func BenchmarkFlushClicks(b *testing.B) {
+	for _, batch := range []int{1, 100} {
+		b.Run(fmt.Sprintf("batch=%d", batch), func(b *testing.B) {
+			s := newTestServer(b)
+			id := firstApp(b, s).ID
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			r.Header.Set("User-Agent", browserUA)
+			ctx := context.Background()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				s.recordClick(r, id)
+				if (i+1)%batch == 0 || i == b.N-1 {
+					if err := s.flushClicks(ctx); err != nil {
+						b.Fatalf("flush: %v", err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestReferrerHost(t *testing.T) {
+	tests := []struct {
+		target, referer, want string
+	}{
+		{"/go/1", "", ""},
+		{"/go/1", "https://www.Google.com/search?q=x", "www.google.com"},
+		{"/go/1", "http://example.com/tag/ki", ""},
+		{"/go/1", "https://example.com:8443/", ""},
+		{"/api/click/1?ref=https%3A%2F%2Fnews.ycombinator.com%2Fitem", "http://example.com/", "news.ycombinator.com"},
+		{"/go/1", "not a url", ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		if tt.referer != "" {
+			r.Header.Set("Referer", tt.referer)
+		}
+		if got := referrerHost(r); got != tt.want {
+			t.Errorf("referrerHost(%s, Referer %q) = %q, want %q", tt.target, tt.referer, got, tt.want)
+		}
+	}
+}
+
+// TestFlushClicksBounded checks that clicks piling up while the database
+// can't be written are capped and the loss is counted once it recovers.
+func TestFlushClicksBounded(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+	id := firstApp(t, s).ID
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.Header.Set("User-Agent", browserUA)
+
+	if _, err := s.DB.Exec("ALTER TABLE click_events RENAME TO click_events_away"); err != nil {
+		t.Fatal(err)
+	}
+	for range maxPendingClicks - 10 {
+		s.recordClick(r, id)
+	}
+	if err := s.flushClicks(ctx); err == nil {
+		t.Fatal("flush succeeded without click_events")
+	}
+	for range 25 {
+		s.recordClick(r, id)
+	}
+	if err := s.flushClicks(ctx); err == nil {
+		t.Fatal("flush succeeded without click_events")
+	}
+	if n := len(s.buffer.pending); n != maxPendingClicks {
+		t.Errorf("%d clicks pending, want %d", n, maxPendingClicks)
+	}
+
+	if _, err := s.DB.Exec("ALTER TABLE click_events_away RENAME TO click_events"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.flushClicks(ctx); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	var events, dropped int64
+	if err := s.DB.QueryRow("SELECT COUNT(*) FROM click_events").Scan(&events); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DB.QueryRow("SELECT COALESCE(SUM(count), 0) FROM click_rejections WHERE reason = ?", rejectDropped).Scan(&dropped); err != nil {
+		t.Fatal(err)
+	}
+	if events != maxPendingClicks || dropped != 15 {
+		t.Errorf("wrote %d clicks and %d dropped, want %d and 15", events, dropped, maxPendingClicks)
+	}
+}
@@ -0,0 +1,137 @@
+package srv
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"srv.exe.dev/db/dbgen"
+)
+
+const (
+	// csrfKeySetting is the settings key of the secret CSRF tokens are
+	// derived from. It is kept in the database so forms stay valid across
+	// restarts.
+	csrfKeySetting = "csrf_key"
+
+	// csrfFormField is the hidden form field that carries the token.
+	csrfFormField = "csrf_token"
+)
+
+// crossOrigin rejects unsafe requests that browsers mark as coming from
+// another site, by Sec-Fetch-Site or, in older browsers, Origin.
+var crossOrigin = http.NewCrossOriginProtection()
+
+type csrfTokenKey struct{}
+
+// loadCSRFKey reads the CSRF secret, creating it on first start.
+func (s *Server) loadCSRFKey(ctx context.Context) error {
+	q := dbgen.New(s.DB)
+	key, err := q.GetSetting(ctx, csrfKeySetting)
+	if errors.Is(err, sql.ErrNoRows) {
+		key = rand.Text()
+		err = q.SetSetting(ctx, dbgen.SetSettingParams{Key: csrfKeySetting, Value: key})
+	}
+	if err != nil {
+		return fmt.Errorf("load csrf key: %w", err)
+	}
+	s.csrfKey = []byte(key)
+	return nil
+}
+
+// csrfToken is the token user's forms must send back, as of now. It is
+// derived from the session, so it needs no storage and dies with the
+// session. exe.dev admins have no session; theirs is derived from their
+// identity and the UTC date instead, so a leaked one goes stale.
+func (s *Server) csrfToken(user admin, now time.Time) string {
+	subject := "session:" + user.Session
+	if user.ExeDev {
+		subject = "exe.dev:" + user.Username + ":" + user.ExeDevID + ":" + now.UTC().Format(time.DateOnly)
+	}
+	mac := hmac.New(sha256.New, s.csrfKey)
+	mac.Write([]byte(subject))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// validCSRFToken reports whether sent is user's token. An exe.dev admin's
+// token from the day before is still taken, so forms loaded just before
+// midnight can be sent just after.
+func (s *Server) validCSRFToken(user admin, sent string, now time.Time) bool {
+	if hmac.Equal([]byte(sent), []byte(s.csrfToken(user, now))) {
+		return true
+	}
+	return user.ExeDev && hmac.Equal([]byte(sent), []byte(s.csrfToken(user, now.AddDate(0, 0, -1))))
+}
+
+// csrfProtect guards admin form posts against cross-site request forgery.
+// Cross-origin browser requests are refused outright, and requests by a
+// logged-in admin must also carry their CSRF token. Requests without an
+// admin are let through: they carry no authority a forger could borrow,
+// and the login and invite forms must work before there is a session.
+//
+// The token of the current admin is put in the request context, where
+// the csrfField template function finds it.
+func (s *Server) csrfProtect(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !adminPath(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if err := crossOrigin.Check(r); err != nil {
+			slog.Warn("csrf: cross-origin request rejected", "url", r.URL.Path, "origin", r.Header.Get("Origin"), "error", err)
+			s.forbidden(w, r, "The form was sent from another website.")
+			return
+		}
+		now := time.Now()
+		user, ok := s.currentAdmin(r)
+		var token string
+		if ok {
+			token = s.csrfToken(user, now)
+		}
+		if ok && !safeMethod(r.Method) {
+			sent := r.PostFormValue(csrfFormField)
+			if !s.validCSRFToken(user, sent, now) {
+				slog.Warn("csrf: bad token", "url", r.URL.Path, "empty", sent == "")
+				s.forbidden(w, r, "The form has expired or was not sent from this website.")
+				return
+			}
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), csrfTokenKey{}, token)))
+	})
+}
+
+func safeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// csrfField is the hidden input with r's CSRF token that every admin form
+// includes. It is empty when nobody is logged in.
+func csrfField(r *http.Request) template.HTML {
+	token, _ := r.Context().Value(csrfTokenKey{}).(string)
+	if token == "" {
+		return ""
+	}
+	return template.HTML(`<input type="hidden" name="` + csrfFormField + `" value="` + token + `">`)
+}
+
+type forbiddenPageData struct {
+	Reason string
+}
+
+// forbidden tells the user why a form post was refused.
+func (s *Server) forbidden(w http.ResponseWriter, r *http.Request, reason string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusForbidden)
+	if err := s.renderTemplate(w, r, "forbidden.html", forbiddenPageData{Reason: reason}); err != nil {
+		slog.Warn("render template", "url", r.URL.Path, "error", err)
+	}
+}
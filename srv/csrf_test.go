@@ -0,0 +1,110 @@
+package srv
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"srv.exe.dev/db/dbgen"
+)
+
+// loginCookie creates an editor and logs them in, returning the session
+// cookie and the admin it belongs to.
+func loginCookie(t *testing.T, s *Server) (*http.Cookie, admin) {
+	t.Helper()
+	user, err := createAdminUser(context.Background(), dbgen.New(s.DB), "erin", "correct horse", roleEditor)
+	if err != nil {
+		t.Fatalf("createAdminUser: %v", err)
+	}
+	w := httptest.NewRecorder()
+	if err := s.startSession(w, httptest.NewRequest("POST", "/admin/login", nil), user); err != nil {
+		t.Fatalf("startSession: %v", err)
+	}
+	for _, c := range w.Result().Cookies() {
+		if c.Name == sessionCookie {
+			return c, admin{AdminUser: user, Session: sessionID(c.Value)}
+		}
+	}
+	t.Fatal("no session cookie set")
+	return nil, admin{}
+}
+
+func TestCSRFProtect(t *testing.T) {
+	s := newTestServer(t)
+	h := s.Handler()
+	cookie, user := loginCookie(t, s)
+	token := s.csrfToken(user, time.Now())
+
+	tests := []struct {
+		name         string
+		token        string
+		secFetchSite string
+		want         int
+		wantNewApp   bool
+	}{
+		{"no token", "", "", http.StatusForbidden, false},
+		{"wrong token", strings.Repeat("0", len(token)), "", http.StatusForbidden, false},
+		{"cross-site", token, "cross-site", http.StatusForbidden, false},
+		{"valid token", token, "same-origin", http.StatusSeeOther, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := dbgen.New(s.DB)
+			before, err := q.ListApps(context.Background())
+			if err != nil {
+				t.Fatalf("ListApps: %v", err)
+			}
+			form := url.Values{
+				"title":       {"CSRF " + tt.name},
+				"url":         {"https://example.org/" + url.PathEscape(tt.name)},
+				"description": {"Test app"},
+			}
+			if tt.token != "" {
+				form.Set(csrfFormField, tt.token)
+			}
+			r := httptest.NewRequest("POST", "/admin/save", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.secFetchSite != "" {
+				r.Header.Set("Sec-Fetch-Site", tt.secFetchSite)
+			}
+			r.AddCookie(cookie)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			after, err := q.ListApps(context.Background())
+			if err != nil {
+				t.Fatalf("ListApps: %v", err)
+			}
+			if added := len(after) > len(before); added != tt.wantNewApp {
+				t.Errorf("app added = %v, want %v", added, tt.wantNewApp)
+			}
+		})
+	}
+}
+
+func TestExeDevCSRFTokenRotates(t *testing.T) {
+	s := newTestServer(t)
+	user := admin{AdminUser: dbgen.AdminUser{Username: "ana@example.org"}, ExeDev: true, ExeDevID: "u123"}
+	now := time.Date(2026, 3, 2, 0, 5, 0, 0, time.UTC)
+
+	if !s.validCSRFToken(user, s.csrfToken(user, now), now) {
+		t.Error("today's token refused")
+	}
+	if !s.validCSRFToken(user, s.csrfToken(user, now.Add(-10*time.Minute)), now) {
+		t.Error("token from just before midnight refused")
+	}
+	if s.validCSRFToken(user, s.csrfToken(user, now.AddDate(0, 0, -2)), now) {
+		t.Error("token from two days ago accepted")
+	}
+	other := user
+	other.ExeDevID = "u456"
+	if s.validCSRFToken(other, s.csrfToken(user, now), now) {
+		t.Error("token of another exe.dev user accepted")
+	}
+}
@@ -0,0 +1,201 @@
+package srv
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"srv.exe.dev/db/dbgen"
+)
+
+const (
+	// Default and largest size of the oEmbed iframe, in CSS pixels.
+	embedWidth     = 380
+	embedHeight    = 420
+	maxEmbedWidth  = 1200
+	maxEmbedHeight = 1200
+
+	// maxEmbedApps caps how many cards one /embed list shows.
+	maxEmbedApps = 12
+)
+
+type embedPageData struct {
+	Apps []appEntry
+}
+
+// HandleEmbed renders one app card for use in an iframe on other sites.
+func (s *Server) HandleEmbed(w http.ResponseWriter, r *http.Request) {
+	app, ok := s.appByIDParam(w, r)
+	if !ok {
+		return
+	}
+	s.renderEmbed(w, r, []appEntry{{App: app}})
+}
+
+// HandleEmbedList renders a set of app cards for an iframe: the apps with
+// ?tag= or ?sdg= if given, in the homepage order, at most ?limit= of them.
+// This is what /widget.js embeds.
+func (s *Server) HandleEmbedList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	apps, err := s.listApps(ctx, appQuery{
+		Ranking:     s.activeRanking(ctx),
+		Tags:        tagParams(r),
+		MatchAnyTag: r.URL.Query().Get("match") == "any",
+		SDG:         sdgParam(r),
+	})
+	if err != nil {
+		slog.Warn("list apps", "error", err)
+	}
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 || limit > maxEmbedApps {
+		limit = maxEmbedApps
+	}
+	if len(apps) > limit {
+		apps = apps[:limit]
+	}
+	s.renderEmbed(w, r, apps)
+}
+
+// renderEmbed counts an embed view for each app and renders the cards.
+func (s *Server) renderEmbed(w http.ResponseWriter, r *http.Request, apps []appEntry) {
+	if r.Method == http.MethodGet {
+		s.countEmbedViews(r, apps)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.renderTemplate(w, r, "embed.html", embedPageData{Apps: apps}); err != nil {
+		slog.Warn("render template", "url", r.URL.Path, "error", err)
+	}
+}
+
+// countEmbedViews counts a view of each app's card, screened like clicks:
+// crawlers are skipped, each address gets its own token bucket, and a
+// visitor seeing the same card again within clickDedupWindow counts once.
+// Embeds have their own guard so they don't use up the click budget.
+func (s *Server) countEmbedViews(r *http.Request, apps []appEntry) {
+	if deviceClass(r.UserAgent()) == "bot" || r.UserAgent() == "" {
+		return
+	}
+	ctx := r.Context()
+	now := time.Now()
+	if ok, _ := s.embeds.allow(s.clientIP(r), now); !ok {
+		return
+	}
+	visitor, err := s.visitorID(ctx, r, now)
+	if err != nil {
+		slog.Warn("embed visitor id", "error", err)
+		return
+	}
+	q := dbgen.New(s.DB)
+	for _, app := range apps {
+		if !s.embeds.firstSeen(visitor, app.ID, now) {
+			continue
+		}
+		if err := q.CountEmbedView(ctx, app.ID); err != nil {
+			slog.Warn("count embed view", "id", app.ID, "error", err)
+		}
+	}
+}
+
+// HandleWidgetJS serves the loader script partners paste into their pages.
+func (s *Server) HandleWidgetJS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	http.ServeFile(w, r, filepath.Join(s.StaticDir, "widget.js"))
+}
+
+// oEmbedResponse is a "rich" oEmbed response (https://oembed.com).
+type oEmbedResponse struct {
+	Version      string `json:"version"`
+	Type         string `json:"type"`
+	Title        string `json:"title"`
+	ProviderName string `json:"provider_name"`
+	ProviderURL  string `json:"provider_url"`
+	HTML         string `json:"html"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+}
+
+// HandleOEmbed answers oEmbed requests for the URL of an app's detail page
+// (/apps/{slug}) or card (/embed/{id}, /go/{id}) on this site.
+func (s *Server) HandleOEmbed(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if f := query.Get("format"); f != "" && f != "json" {
+		http.Error(w, "only format=json is supported", http.StatusNotImplemented)
+		return
+	}
+	app, err := s.appForURL(r, query.Get("url"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	width, height := embedWidth, embedHeight
+	if mw, err := strconv.Atoi(query.Get("maxwidth")); err == nil && mw > 0 {
+		width = min(width, mw, maxEmbedWidth)
+	}
+	if mh, err := strconv.Atoi(query.Get("maxheight")); err == nil && mh > 0 {
+		height = min(height, mh, maxEmbedHeight)
+	}
+
+	base := s.baseURL(r)
+	src := fmt.Sprintf("%s/embed/%d", base, app.ID)
+	html := fmt.Sprintf(`<iframe src="%s" width="%d" height="%d" style="border:0;max-width:100%%" loading="lazy" title="%s"></iframe>`,
+		template.HTMLEscapeString(src), width, height, template.HTMLEscapeString(app.Title))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	json.NewEncoder(w).Encode(oEmbedResponse{
+		Version:      "1.0",
+		Type:         "rich",
+		Title:        app.Title,
+		ProviderName: "Kohlschwarz Think-Tank",
+		ProviderURL:  base + "/",
+		HTML:         html,
+		Width:        width,
+		Height:       height,
+	})
+}
+
+// appForURL finds the app a URL on this site points at.
+func (s *Server) appForURL(r *http.Request, raw string) (dbgen.App, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return dbgen.App{}, errors.New("url must be an absolute URL")
+	}
+	if site, _ := url.Parse(s.baseURL(r)); site == nil || !strings.EqualFold(u.Host, site.Host) {
+		return dbgen.App{}, errors.New("url is not on this site")
+	}
+
+	q := dbgen.New(s.DB)
+	ctx := r.Context()
+	if slug, ok := strings.CutPrefix(u.Path, "/apps/"); ok {
+		app, err := q.GetAppBySlug(ctx, strings.TrimSuffix(slug, "/"))
+		if err != nil {
+			return dbgen.App{}, errors.New("no such app")
+		}
+		return app, nil
+	}
+	for _, prefix := range []string{"/embed/", "/go/"} {
+		if rest, ok := strings.CutPrefix(u.Path, prefix); ok {
+			id, err := strconv.ParseInt(strings.TrimSuffix(rest, "/"), 10, 64)
+			if err != nil {
+				break
+			}
+			app, err := q.GetApp(ctx, id)
+			if err != nil {
+				return dbgen.App{}, errors.New("no such app")
+			}
+			return app, nil
+		}
+	}
+	return dbgen.App{}, errors.New("url does not point at an app")
+}
@@ -0,0 +1,143 @@
+package srv
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"srv.exe.dev/db/dbgen"
+)
+
+// Identity headers the exe.dev proxy adds for signed-in users, and its
+// login page, which it serves on every proxied host next to
+// /__exe.dev/logout.
+const (
+	exeDevEmailHeader  = "X-ExeDev-Email"
+	exeDevUserIDHeader = "X-ExeDev-UserID"
+	exeDevLoginPath    = "/__exe.dev/login"
+)
+
+// ExeDevAuth lets admins in by the identity headers of the exe.dev proxy
+// instead of a password. It is off unless both fields are set.
+type ExeDevAuth struct {
+	// TrustedProxies are the addresses the proxy connects from. Identity
+	// headers on requests from anywhere else are ignored, since any
+	// client can send them.
+	TrustedProxies []netip.Prefix
+
+	// Admins maps lower-case email addresses to the role they get.
+	Admins map[string]role
+}
+
+// NewExeDevAuth parses proxy ranges (CIDRs or single addresses) and an
+// email to role name allowlist.
+func NewExeDevAuth(proxies []string, admins map[string]string) (ExeDevAuth, error) {
+	var a ExeDevAuth
+	for _, p := range proxies {
+		prefix, err := netip.ParsePrefix(p)
+		if err != nil {
+			addr, aerr := netip.ParseAddr(p)
+			if aerr != nil {
+				return ExeDevAuth{}, fmt.Errorf("trusted proxy %q: %w", p, err)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		a.TrustedProxies = append(a.TrustedProxies, prefix.Masked())
+	}
+	if len(admins) > 0 {
+		a.Admins = make(map[string]role, len(admins))
+	}
+	for email, name := range admins {
+		rl, err := parseRole(name)
+		if err != nil {
+			return ExeDevAuth{}, fmt.Errorf("admin email %q: %w", email, err)
+		}
+		a.Admins[strings.ToLower(strings.TrimSpace(email))] = rl
+	}
+	return a, nil
+}
+
+// Enabled reports whether exe.dev logins are configured.
+func (a ExeDevAuth) Enabled() bool {
+	return len(a.TrustedProxies) > 0 && len(a.Admins) > 0
+}
+
+// fromTrustedProxy reports whether r's TCP peer is in a trusted range.
+// Forwarding headers are deliberately not consulted.
+func (a ExeDevAuth) fromTrustedProxy(r *http.Request) bool {
+	addrPort, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	addr := addrPort.Addr().Unmap()
+	for _, p := range a.TrustedProxies {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// exeDevWarnInterval is the least time between two warnings about
+// identity headers from untrusted addresses, so clients sending them can't
+// flood the log.
+const exeDevWarnInterval = time.Minute
+
+// ignoredHeaders counts requests whose identity headers were ignored and
+// decides when that is worth a warning.
+type ignoredHeaders struct {
+	mu       sync.Mutex
+	count    int64
+	lastWarn time.Time
+}
+
+// add counts one ignored request. It returns how many were ignored since
+// the last warning, including this one, and whether to warn now.
+func (h *ignoredHeaders) add(now time.Time) (int64, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.count++
+	if now.Sub(h.lastWarn) < exeDevWarnInterval {
+		return h.count, false
+	}
+	n := h.count
+	h.count, h.lastWarn = 0, now
+	return n, true
+}
+
+// exeDevAdmin returns the admin named by r's identity headers, if r comes
+// through a trusted proxy and the email is on the allowlist. The admin has
+// no account row; ID is 0.
+func (s *Server) exeDevAdmin(r *http.Request) (admin, bool) {
+	email := strings.ToLower(strings.TrimSpace(r.Header.Get(exeDevEmailHeader)))
+	if email == "" || !s.ExeDev.Enabled() {
+		return admin{}, false
+	}
+	if !s.ExeDev.fromTrustedProxy(r) {
+		if n, warn := s.ignoredHeaders.add(time.Now()); warn {
+			slog.Warn("ignoring exe.dev identity headers from untrusted address", "remote", r.RemoteAddr, "email", email, "ignored", n)
+		} else {
+			slog.Debug("ignoring exe.dev identity headers from untrusted address", "remote", r.RemoteAddr, "email", email)
+		}
+		return admin{}, false
+	}
+	rl, ok := s.ExeDev.Admins[email]
+	if !ok {
+		return admin{}, false
+	}
+	return admin{
+		AdminUser: dbgen.AdminUser{Username: email, Role: string(rl)},
+		ExeDev:    true,
+		ExeDevID:  r.Header.Get(exeDevUserIDHeader),
+	}, true
+}
+
+// exeDevLoginURL is the proxy's login page, returning to next afterwards.
+func exeDevLoginURL(next string) string {
+	return exeDevLoginPath + "?redirect=" + url.QueryEscape(next)
+}
@@ -0,0 +1,77 @@
+package srv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestExeDevHeaders(t *testing.T) {
+	s := newTestServer(t)
+	var err error
+	s.ExeDev, err = NewExeDevAuth([]string{"10.0.0.0/8"}, map[string]string{"Ana@Example.org": "viewer"})
+	if err != nil {
+		t.Fatalf("NewExeDevAuth: %v", err)
+	}
+	h := s.Handler()
+	const toLogin = "/admin/login?next=%2Fadmin"
+
+	tests := []struct {
+		name         string
+		remote       string
+		forwardedFor string
+		email        string
+		want         int
+		location     string
+	}{
+		{"untrusted proxy", "203.0.113.5:1234", "", "ana@example.org", http.StatusSeeOther, toLogin},
+		{"untrusted proxy, forged X-Forwarded-For", "203.0.113.5:1234", "10.1.2.3", "ana@example.org", http.StatusSeeOther, toLogin},
+		{"trusted proxy", "10.1.2.3:1234", "", "ANA@example.ORG", http.StatusOK, ""},
+		{"trusted proxy, email not allowed", "10.1.2.3:1234", "", "eve@example.org", http.StatusSeeOther, toLogin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/admin", nil)
+			r.RemoteAddr = tt.remote
+			r.Header.Set(exeDevEmailHeader, tt.email)
+			r.Header.Set(exeDevUserIDHeader, "u123")
+			if tt.forwardedFor != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if loc := w.Header().Get("Location"); loc != tt.location {
+				t.Errorf("Location = %q, want %q", loc, tt.location)
+			}
+		})
+	}
+}
+
+// TestIgnoredHeadersWarnings checks that forged identity headers are
+// warned about at most once per exeDevWarnInterval, with a count.
+func TestIgnoredHeadersWarnings(t *testing.T) {
+	var h ignoredHeaders
+	start := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	steps := []struct {
+		after time.Duration
+		n     int64
+		warn  bool
+	}{
+		{0, 1, true},
+		{time.Second, 1, false},
+		{2 * time.Second, 2, false},
+		{exeDevWarnInterval - time.Second, 3, false},
+		{exeDevWarnInterval, 4, true},
+		{exeDevWarnInterval + time.Second, 1, false},
+	}
+	for _, st := range steps {
+		n, warn := h.add(start.Add(st.after))
+		if n != st.n || warn != st.warn {
+			t.Errorf("after %v: add = %d, %v; want %d, %v", st.after, n, warn, st.n, st.warn)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+package srv
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path"
+	"time"
+
+	"srv.exe.dev/db/dbgen"
+)
+
+const (
+	// feedSize is how many of the newest apps the feeds carry.
+	feedSize = 50
+
+	feedTitle       = "Kohlschwarz Think-Tank"
+	feedDescription = "Neue Civic Data Apps für Österreich"
+	feedAuthor      = "Kohlschwarz Think-Tank"
+)
+
+// feedItem is one app as it appears in every feed format.
+type feedItem struct {
+	ID        string // permanent URL of the app's detail page
+	Title     string
+	URL       string // the app itself
+	Summary   string
+	Content   string
+	Image     string
+	ImageType string
+	Published time.Time
+	Updated   time.Time
+}
+
+// feedItems loads the newest apps, newest first, with absolute URLs for r.
+func (s *Server) feedItems(r *http.Request) ([]feedItem, error) {
+	apps, err := dbgen.New(s.DB).ListNewestApps(r.Context(), feedSize)
+	if err != nil {
+		return nil, err
+	}
+	base := s.baseURL(r)
+	items := make([]feedItem, len(apps))
+	for i, app := range apps {
+		item := feedItem{
+			ID:        base + "/apps/" + app.Slug,
+			Title:     app.Title,
+			URL:       app.Url,
+			Summary:   app.Description,
+			Content:   app.Description,
+			Published: app.CreatedAt.UTC(),
+			Updated:   app.UpdatedAt.UTC(),
+		}
+		if app.Prompt != nil && *app.Prompt != "" {
+			item.Content = *app.Prompt
+		}
+		if app.Thumbnail != nil && *app.Thumbnail != "" {
+			item.Image = absURL(base, *app.Thumbnail)
+			item.ImageType = mime.TypeByExtension(path.Ext(*app.Thumbnail))
+			if item.ImageType == "" {
+				item.ImageType = "image/jpeg"
+			}
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+// feedUpdated is the newest update among items, or the Unix epoch for an
+// empty feed.
+func feedUpdated(items []feedItem) time.Time {
+	latest := time.Unix(0, 0).UTC()
+	for _, it := range items {
+		if it.Updated.After(latest) {
+			latest = it.Updated
+		}
+	}
+	return latest
+}
+
+// serveFeed writes body with an ETag and Last-Modified, answering
+// conditional requests with 304 Not Modified.
+func serveFeed(w http.ResponseWriter, r *http.Request, contentType string, modified time.Time, body []byte) {
+	sum := sha256.Sum256(body)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	http.ServeContent(w, r, "", modified, bytes.NewReader(body))
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Lang    string      `xml:"xml:lang,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomPerson  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Summary   string     `xml:"summary"`
+	Content   atomText   `xml:"content"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// HandleAtomFeed serves /feed.xml.
+func (s *Server) HandleAtomFeed(w http.ResponseWriter, r *http.Request) {
+	items, err := s.feedItems(r)
+	if err != nil {
+		slog.Warn("feed", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	base := s.baseURL(r)
+	updated := feedUpdated(items)
+	feed := atomFeed{
+		Lang:    "de-AT",
+		ID:      base + "/",
+		Title:   feedTitle,
+		Updated: updated.Format(time.RFC3339),
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: base + "/feed.xml"},
+			{Rel: "alternate", Type: "text/html", Href: base + "/"},
+		},
+		Author: atomPerson{Name: feedAuthor},
+	}
+	for _, it := range items {
+		e := atomEntry{
+			ID:        it.ID,
+			Title:     it.Title,
+			Links:     []atomLink{{Rel: "alternate", Type: "text/html", Href: it.ID}, {Rel: "related", Href: it.URL}},
+			Published: it.Published.Format(time.RFC3339),
+			Updated:   it.Updated.Format(time.RFC3339),
+			Summary:   it.Summary,
+			Content:   atomText{Type: "text", Body: it.Content},
+		}
+		if it.Image != "" {
+			e.Links = append(e.Links, atomLink{Rel: "enclosure", Type: it.ImageType, Href: it.Image})
+		}
+		feed.Entries = append(feed.Entries, e)
+	}
+
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		slog.Warn("encode atom feed", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	serveFeed(w, r, "application/atom+xml; charset=utf-8", updated, append([]byte(xml.Header), body...))
+}
+
+type rssFeed struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	AtomNS    string     `xml:"xmlns:atom,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Self          rssSelf   `xml:"atom:link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssSelf struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        rssGUID       `xml:"guid"`
+	PubDate     string        `xml:"pubDate"`
+	Description string        `xml:"description"`
+	Content     string        `xml:"content:encoded"`
+	Enclosure   *rssEnclosure `xml:"enclosure"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int    `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// HandleRSSFeed serves /rss.xml.
+func (s *Server) HandleRSSFeed(w http.ResponseWriter, r *http.Request) {
+	items, err := s.feedItems(r)
+	if err != nil {
+		slog.Warn("feed", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	base := s.baseURL(r)
+	updated := feedUpdated(items)
+	feed := rssFeed{
+		Version:   "2.0",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		Channel: rssChannel{
+			Title:         feedTitle,
+			Link:          base + "/",
+			Self:          rssSelf{Href: base + "/rss.xml", Rel: "self", Type: "application/rss+xml"},
+			Description:   feedDescription,
+			Language:      "de-at",
+			LastBuildDate: updated.Format(time.RFC1123Z),
+		},
+	}
+	for _, it := range items {
+		item := rssItem{
+			Title:       it.Title,
+			Link:        it.ID,
+			GUID:        rssGUID{IsPermaLink: true, Value: it.ID},
+			PubDate:     it.Published.Format(time.RFC1123Z),
+			Description: it.Summary,
+			Content:     it.Content,
+		}
+		if it.Image != "" {
+			// The size isn't known without fetching the file; 0 is the
+			// customary placeholder.
+			item.Enclosure = &rssEnclosure{URL: it.Image, Type: it.ImageType}
+		}
+		feed.Channel.Items = append(feed.Channel.Items, item)
+	}
+
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		slog.Warn("encode rss feed", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	serveFeed(w, r, "application/rss+xml; charset=utf-8", updated, append([]byte(xml.Header), body...))
+}
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description"`
+	Language    string         `json:"language"`
+	Authors     []jsonAuthor   `json:"authors"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonFeedItem struct {
+	ID            string    `json:"id"`
+	URL           string    `json:"url"`
+	ExternalURL   string    `json:"external_url"`
+	Title         string    `json:"title"`
+	ContentText   string    `json:"content_text"`
+	Summary       string    `json:"summary"`
+	Image         string    `json:"image,omitempty"`
+	DatePublished time.Time `json:"date_published"`
+	DateModified  time.Time `json:"date_modified"`
+}
+
+// HandleJSONFeed serves /feed.json.
+func (s *Server) HandleJSONFeed(w http.ResponseWriter, r *http.Request) {
+	items, err := s.feedItems(r)
+	if err != nil {
+		slog.Warn("feed", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	base := s.baseURL(r)
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       feedTitle,
+		HomePageURL: base + "/",
+		FeedURL:     base + "/feed.json",
+		Description: feedDescription,
+		Language:    "de-AT",
+		Authors:     []jsonAuthor{{Name: feedAuthor}},
+		Items:       make([]jsonFeedItem, len(items)),
+	}
+	for i, it := range items {
+		feed.Items[i] = jsonFeedItem{
+			ID:            it.ID,
+			URL:           it.ID,
+			ExternalURL:   it.URL,
+			Title:         it.Title,
+			ContentText:   it.Content,
+			Summary:       it.Summary,
+			Image:         it.Image,
+			DatePublished: it.Published,
+			DateModified:  it.Updated,
+		}
+	}
+
+	body, err := json.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		slog.Warn("encode json feed", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	serveFeed(w, r, "application/feed+json; charset=utf-8", feedUpdated(items), body)
+}
@@ -0,0 +1,80 @@
+package srv
+
+import (
+	"time"
+)
+
+// appItemList is the schema.org ItemList of the apps shown on the homepage.
+type appItemList struct {
+	Context         string        `json:"@context"`
+	Type            string        `json:"@type"`
+	Name            string        `json:"name"`
+	NumberOfItems   int           `json:"numberOfItems"`
+	ItemListOrder   string        `json:"itemListOrder"`
+	ItemListElement []appListItem `json:"itemListElement"`
+}
+
+type appListItem struct {
+	Type     string         `json:"@type"`
+	Position int            `json:"position"`
+	Item     webApplication `json:"item"`
+}
+
+// webApplication describes one app as a schema.org WebApplication. Context
+// is only set when it stands alone, on the app's own page.
+type webApplication struct {
+	Context             string    `json:"@context,omitempty"`
+	Type                string    `json:"@type"`
+	ID                  string    `json:"@id,omitempty"`
+	Name                string    `json:"name"`
+	URL                 string    `json:"url"`
+	Description         string    `json:"description,omitempty"`
+	Image               string    `json:"image,omitempty"`
+	ApplicationCategory string    `json:"applicationCategory"`
+	OperatingSystem     string    `json:"operatingSystem"`
+	DateCreated         time.Time `json:"dateCreated"`
+	DateModified        time.Time `json:"dateModified"`
+	InLanguage          string    `json:"inLanguage"`
+	About               []sdgTerm `json:"about,omitempty"`
+}
+
+// appListJSONLD builds the ItemList for apps, in the order they are shown.
+// base is the site origin for absolute URLs.
+func appListJSONLD(base string, apps []appEntry) appItemList {
+	list := appItemList{
+		Context:         "https://schema.org",
+		Type:            "ItemList",
+		Name:            "Civic Data Apps",
+		NumberOfItems:   len(apps),
+		ItemListOrder:   "https://schema.org/ItemListOrderAscending",
+		ItemListElement: make([]appListItem, len(apps)),
+	}
+	for i, app := range apps {
+		list.ItemListElement[i] = appListItem{Type: "ListItem", Position: i + 1, Item: appJSONLD(base, app)}
+	}
+	return list
+}
+
+// appJSONLD describes app as a WebApplication identified by its detail
+// page.
+func appJSONLD(base string, app appEntry) webApplication {
+	item := webApplication{
+		Type:                "WebApplication",
+		Name:                app.Title,
+		URL:                 app.Url,
+		Description:         app.Description,
+		ApplicationCategory: "ReferenceApplication",
+		OperatingSystem:     "Web",
+		DateCreated:         app.CreatedAt.UTC(),
+		DateModified:        app.UpdatedAt.UTC(),
+		InLanguage:          "de-AT",
+		About:               sdgTerms(app.Goals()),
+	}
+	if app.Slug != "" {
+		item.ID = absURL(base, "/apps/"+app.Slug)
+	}
+	if app.Thumbnail != nil {
+		item.Image = absURL(base, *app.Thumbnail)
+	}
+	return item
+}
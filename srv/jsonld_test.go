@@ -0,0 +1,113 @@
+package srv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+)
+
+var ldJSONBlock = regexp.MustCompile(`(?s)<script type="application/ld\+json">(.*?)</script>`)
+
+// ldJSON returns the JSON-LD blocks of an HTML page, decoded.
+func ldJSON(t *testing.T, body string) []map[string]any {
+	t.Helper()
+	var blocks []map[string]any
+	for _, m := range ldJSONBlock.FindAllStringSubmatch(body, -1) {
+		var v map[string]any
+		if err := json.Unmarshal([]byte(m[1]), &v); err != nil {
+			t.Fatalf("JSON-LD block is not valid JSON: %v\n%s", err, m[1])
+		}
+		blocks = append(blocks, v)
+	}
+	return blocks
+}
+
+// checkWebApplication checks the properties every app's WebApplication
+// must have.
+func checkWebApplication(t *testing.T, item map[string]any) {
+	t.Helper()
+	if item["@type"] != "WebApplication" {
+		t.Errorf("@type = %v, want WebApplication", item["@type"])
+	}
+	for _, key := range []string{"name", "url", "description", "applicationCategory", "operatingSystem"} {
+		if s, _ := item[key].(string); s == "" {
+			t.Errorf("%s missing in %v", key, item)
+		}
+	}
+	for _, key := range []string{"dateCreated", "dateModified"} {
+		s, _ := item[key].(string)
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			t.Errorf("%s = %q, want an ISO 8601 date: %v", key, s, err)
+		}
+	}
+}
+
+func TestAppPageJSONLD(t *testing.T) {
+	s := newTestServer(t)
+	app := firstApp(t, s)
+
+	r := httptest.NewRequest(http.MethodGet, "/apps/"+app.Slug, nil)
+	r.SetPathValue("slug", app.Slug)
+	w := httptest.NewRecorder()
+	s.HandleApp(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /apps/%s: status %d", app.Slug, w.Code)
+	}
+
+	blocks := ldJSON(t, w.Body.String())
+	if len(blocks) != 1 {
+		t.Fatalf("got %d JSON-LD blocks, want 1", len(blocks))
+	}
+	ld := blocks[0]
+	if ld["@context"] != "https://schema.org" {
+		t.Errorf("@context = %v, want https://schema.org", ld["@context"])
+	}
+	checkWebApplication(t, ld)
+	if ld["name"] != app.Title || ld["url"] != app.Url {
+		t.Errorf("name, url = %v, %v, want %q, %q", ld["name"], ld["url"], app.Title, app.Url)
+	}
+	if want := "http://example.com/apps/" + app.Slug; ld["@id"] != want {
+		t.Errorf("@id = %v, want %s", ld["@id"], want)
+	}
+}
+
+func TestHomepageJSONLD(t *testing.T) {
+	s := newTestServer(t)
+
+	w := httptest.NewRecorder()
+	s.HandleRoot(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /: status %d", w.Code)
+	}
+
+	var list map[string]any
+	for _, b := range ldJSON(t, w.Body.String()) {
+		if b["@type"] == "ItemList" {
+			list = b
+		}
+	}
+	if list == nil {
+		t.Fatal("no ItemList JSON-LD on the homepage")
+	}
+	if list["@context"] != "https://schema.org" {
+		t.Errorf("@context = %v, want https://schema.org", list["@context"])
+	}
+	items, _ := list["itemListElement"].([]any)
+	if n, _ := list["numberOfItems"].(float64); len(items) == 0 || int(n) != len(items) {
+		t.Fatalf("numberOfItems = %v with %d items", list["numberOfItems"], len(items))
+	}
+	for i, it := range items {
+		li, _ := it.(map[string]any)
+		if li["@type"] != "ListItem" || li["position"] != float64(i+1) {
+			t.Errorf("item %d: @type %v, position %v", i, li["@type"], li["position"])
+		}
+		app, _ := li["item"].(map[string]any)
+		if _, ok := app["@context"]; ok {
+			t.Errorf("item %d repeats @context", i)
+		}
+		checkWebApplication(t, app)
+	}
+}
@@ -0,0 +1,192 @@
+package srv
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"srv.exe.dev/db/dbgen"
+)
+
+// Kinds of login throttle: failures are counted per client address and
+// per username tried.
+const (
+	throttleIP       = "ip"
+	throttleUsername = "username"
+)
+
+// Reasons a login failed, as stored in login_failures.
+const (
+	loginFailedPassword = "password"
+	loginFailedLocked   = "locked"
+)
+
+const (
+	// loginFreeFailures is how many failed logins in a row are let pass.
+	// Each further one locks the IP and username out for twice as long
+	// as the one before, starting at loginBackoffBase, up to
+	// loginMaxLockout.
+	loginFreeFailures = 3
+	loginBackoffBase  = 2 * time.Second
+	loginMaxLockout   = 15 * time.Minute
+
+	// loginFailureWindow is how long failures are remembered. A key
+	// without failures for this long starts counting from zero again.
+	loginFailureWindow = 24 * time.Hour
+
+	// loginAuditRetention is how long failed logins stay in the audit log,
+	// and loginAuditShown how many of them the users page lists.
+	loginAuditRetention = 90 * 24 * time.Hour
+	loginAuditShown     = 50
+)
+
+// loginBackoff is how long a key is locked out after its nth failure.
+func loginBackoff(failures int64) time.Duration {
+	n := failures - loginFreeFailures
+	if n <= 0 {
+		return 0
+	}
+	if n > 20 {
+		return loginMaxLockout
+	}
+	return min(loginBackoffBase<<(n-1), loginMaxLockout)
+}
+
+// throttleKey names one login throttle.
+type throttleKey struct {
+	kind, key string
+}
+
+// loginThrottleKeys are the throttles a login attempt for username from
+// ip counts against.
+func loginThrottleKeys(ip, username string) []throttleKey {
+	keys := []throttleKey{{throttleIP, ip}}
+	if username != "" {
+		keys = append(keys, throttleKey{throttleUsername, username})
+	}
+	return keys
+}
+
+// countLoginAttempt counts a login attempt against keys before its
+// password is checked, so parallel guesses can't all get in before the
+// first failure is written. It returns how long keys were already locked
+// out, in which case nothing is counted and the password must not be
+// checked, and otherwise how long this attempt locks them out should it
+// fail. A good login clears the count with clearLoginThrottles.
+//
+// An error means the attempt could not be counted and must be refused.
+func (s *Server) countLoginAttempt(ctx context.Context, keys []throttleKey, now time.Time) (locked, backoff time.Duration, err error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+	q := dbgen.New(tx)
+
+	// Writing before reading makes the transaction take SQLite's write
+	// lock up front, so concurrent attempts wait for each other instead of
+	// all reading the same counts.
+	err = q.DeleteStaleLoginThrottles(ctx, dbgen.DeleteStaleLoginThrottlesParams{
+		Now:         now,
+		WindowStart: now.Add(-loginFailureWindow),
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("delete stale login throttles: %w", err)
+	}
+	for _, k := range keys {
+		t, err := q.GetLoginThrottle(ctx, dbgen.GetLoginThrottleParams{Kind: k.kind, Key: k.key})
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return 0, 0, fmt.Errorf("get login throttle: %w", err)
+		}
+		locked = max(locked, t.LockedUntil.Sub(now))
+	}
+	if locked > 0 {
+		return locked, 0, tx.Commit()
+	}
+	for _, k := range keys {
+		failures, err := q.RecordLoginFailure(ctx, dbgen.RecordLoginFailureParams{Kind: k.kind, Key: k.key, Now: now})
+		if err != nil {
+			return 0, 0, fmt.Errorf("record login attempt: %w", err)
+		}
+		b := loginBackoff(failures)
+		if b == 0 {
+			continue
+		}
+		err = q.SetLoginLockedUntil(ctx, dbgen.SetLoginLockedUntilParams{
+			LockedUntil: now.Add(b),
+			Kind:        k.kind,
+			Key:         k.key,
+		})
+		if err != nil {
+			return 0, 0, fmt.Errorf("set login lockout: %w", err)
+		}
+		backoff = max(backoff, b)
+	}
+	return 0, backoff, tx.Commit()
+}
+
+// auditLoginFailure adds a failed login to the audit log.
+func (s *Server) auditLoginFailure(ctx context.Context, ip, username, reason string, now time.Time) {
+	q := dbgen.New(s.DB)
+	err := q.CreateLoginFailure(ctx, dbgen.CreateLoginFailureParams{
+		Ip:        ip,
+		Username:  username,
+		Reason:    reason,
+		CreatedAt: now,
+	})
+	if err != nil {
+		slog.Warn("audit login failure", "error", err)
+	}
+	if err := q.DeleteOldLoginFailures(ctx, now.Add(-loginAuditRetention)); err != nil {
+		slog.Warn("delete old login failures", "error", err)
+	}
+}
+
+// clearLoginThrottles forgets the failures of keys after a good login.
+func (s *Server) clearLoginThrottles(ctx context.Context, keys []throttleKey) {
+	q := dbgen.New(s.DB)
+	for _, k := range keys {
+		if err := q.DeleteLoginThrottle(ctx, dbgen.DeleteLoginThrottleParams{Kind: k.kind, Key: k.key}); err != nil {
+			slog.Warn("clear login throttle", "kind", k.kind, "error", err)
+		}
+	}
+}
+
+// setRetryAfter tells the client to wait at least d, in whole seconds.
+func setRetryAfter(w http.ResponseWriter, d time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int((d+time.Second-1)/time.Second)))
+}
+
+// lockoutMessage is the login form error for a lockout lasting d.
+func lockoutMessage(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("Too many failed logins. Please try again in %d seconds.", int((d+time.Second-1)/time.Second))
+	}
+	return fmt.Sprintf("Too many failed logins. Please try again in %d minutes.", int((d+time.Minute-1)/time.Minute))
+}
+
+// HandleAdminLockoutClear lifts a login lockout before it runs out.
+func (s *Server) HandleAdminLockoutClear(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.requireRole(w, r, roleOwner)
+	if !ok {
+		return
+	}
+	kind, key := r.FormValue("kind"), r.FormValue("key")
+	if kind != throttleIP && kind != throttleUsername {
+		http.Error(w, "bad lockout kind", http.StatusBadRequest)
+		return
+	}
+	if err := dbgen.New(s.DB).DeleteLoginThrottle(r.Context(), dbgen.DeleteLoginThrottleParams{Kind: kind, Key: key}); err != nil {
+		slog.Warn("clear login throttle", "kind", kind, "error", err)
+	}
+	slog.Info("admin login lockout cleared", "by", user.Username, "kind", kind, "key", key)
+	http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
+}
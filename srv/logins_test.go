@@ -0,0 +1,81 @@
+package srv
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"srv.exe.dev/db/dbgen"
+)
+
+// TestLoginThrottleParallel guesses passwords in parallel: only the free
+// failures and the one that starts the lockout may reach the password
+// check, all others must be turned away.
+func TestLoginThrottleParallel(t *testing.T) {
+	s := newTestServer(t)
+	if _, err := createAdminUser(context.Background(), dbgen.New(s.DB), "erin", "correct horse", roleOwner); err != nil {
+		t.Fatalf("createAdminUser: %v", err)
+	}
+	h := s.Handler()
+
+	const attempts = 30
+	codes := make(chan int, attempts)
+	var wg sync.WaitGroup
+	for range attempts {
+		wg.Go(func() {
+			form := url.Values{"username": {"erin"}, "password": {"guess"}}
+			r := httptest.NewRequest("POST", "/admin/login", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.RemoteAddr = "203.0.113.5:1234"
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			codes <- w.Code
+		})
+	}
+	wg.Wait()
+	close(codes)
+
+	count := map[int]int{}
+	for code := range codes {
+		count[code]++
+	}
+	if n := count[http.StatusUnauthorized]; n > loginFreeFailures+1 {
+		t.Errorf("%d attempts reached the password check, want at most %d (all: %v)", n, loginFreeFailures+1, count)
+	}
+	if n := count[http.StatusTooManyRequests] + count[http.StatusServiceUnavailable] + count[http.StatusUnauthorized]; n != attempts {
+		t.Errorf("unexpected responses: %v", count)
+	}
+}
+
+// TestLoginRefusedWithoutThrottle checks that a login the throttle can't
+// count is refused rather than let through.
+func TestLoginRefusedWithoutThrottle(t *testing.T) {
+	s := newTestServer(t)
+	if _, err := createAdminUser(context.Background(), dbgen.New(s.DB), "erin", "correct horse", roleOwner); err != nil {
+		t.Fatalf("createAdminUser: %v", err)
+	}
+	if _, err := s.DB.Exec("DROP TABLE login_throttles"); err != nil {
+		t.Fatalf("drop login_throttles: %v", err)
+	}
+
+	form := url.Values{"username": {"erin"}, "password": {"correct horse"}}
+	r := httptest.NewRequest("POST", "/admin/login", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, r)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("no Retry-After")
+	}
+	for _, c := range w.Result().Cookies() {
+		if c.Name == sessionCookie && c.Value != "" {
+			t.Error("session started")
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package srv
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg"
+	"image/png"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+
+	"srv.exe.dev/db/dbgen"
+)
+
+// Size of generated Open Graph images, as recommended by Facebook and X.
+const (
+	ogWidth  = 1200
+	ogHeight = 630
+)
+
+// ogCache serialises image generation so concurrent requests for the same
+// app don't render it twice.
+type ogCache struct {
+	mu sync.Mutex
+}
+
+var (
+	ogFontsOnce sync.Once
+	ogTitleFace font.Face
+	ogBrandFace font.Face
+	ogFontsErr  error
+)
+
+// ogFonts parses the embedded Go fonts on first use.
+func ogFonts() (title, brand font.Face, err error) {
+	ogFontsOnce.Do(func() {
+		newFace := func(ttf []byte, size float64) (font.Face, error) {
+			f, err := opentype.Parse(ttf)
+			if err != nil {
+				return nil, err
+			}
+			return opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+		}
+		if ogTitleFace, ogFontsErr = newFace(gobold.TTF, 64); ogFontsErr != nil {
+			return
+		}
+		ogBrandFace, ogFontsErr = newFace(gomono.TTF, 28)
+	})
+	return ogTitleFace, ogBrandFace, ogFontsErr
+}
+
+// ogImageURL is the address of app's share image. It carries updated_at,
+// so an edited app gets a new URL that no browser or crawler has cached.
+func ogImageURL(base string, app dbgen.App) string {
+	return fmt.Sprintf("%s/og/%d.png?v=%d", base, app.ID, app.UpdatedAt.Unix())
+}
+
+// HandleOGImage serves /og/{id}.png, the share image of an app. Images are
+// rendered once and kept in CacheDir; the file name carries updated_at, so
+// editing an app yields a new image. Requests for the current version, as
+// linked by ogImageURL, may be cached for good; others only briefly.
+func (s *Server) HandleOGImage(w http.ResponseWriter, r *http.Request) {
+	num, ok := strings.CutSuffix(r.PathValue("file"), ".png")
+	id, err := strconv.ParseInt(num, 10, 64)
+	if !ok || err != nil || id <= 0 {
+		s.notFound(w, r)
+		return
+	}
+	app, err := dbgen.New(s.DB).GetApp(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		s.notFound(w, r)
+		return
+	}
+	if err != nil {
+		slog.Warn("get app", "id", id, "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	path, err := s.ogImageFile(app)
+	if err != nil {
+		slog.Warn("og image", "id", id, "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	if r.URL.Query().Get("v") == strconv.FormatInt(app.UpdatedAt.Unix(), 10) {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age=300")
+	}
+	http.ServeFile(w, r, path)
+}
+
+// ogImageFile returns the path of the cached image for app, rendering it
+// and removing images of older versions first if needed.
+func (s *Server) ogImageFile(app dbgen.App) (string, error) {
+	dir := filepath.Join(s.CacheDir, "og")
+	path := filepath.Join(dir, fmt.Sprintf("%d-%d.png", app.ID, app.UpdatedAt.Unix()))
+
+	s.og.mu.Lock()
+	defer s.og.mu.Unlock()
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	old, _ := filepath.Glob(filepath.Join(dir, fmt.Sprintf("%d-*.png", app.ID)))
+	for _, f := range old {
+		os.Remove(f)
+	}
+
+	img, err := s.renderOGImage(app)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return "", err
+	}
+	return path, os.Rename(tmp, path)
+}
+
+// renderOGImage draws the app's thumbnail across the whole image, darkens
+// the lower part and writes the title and site name on it.
+func (s *Server) renderOGImage(app dbgen.App) (image.Image, error) {
+	titleFace, brandFace, err := ogFonts()
+	if err != nil {
+		return nil, fmt.Errorf("load fonts: %w", err)
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, ogWidth, ogHeight))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(color.RGBA{0x11, 0x11, 0x11, 0xff}), image.Point{}, draw.Src)
+
+	if thumb := s.loadThumbnail(app); thumb != nil {
+		xdraw.CatmullRom.Scale(canvas, canvas.Bounds(), thumb, coverRect(thumb.Bounds(), ogWidth, ogHeight), draw.Src, nil)
+		// Fade to near-black towards the bottom so white text stays legible.
+		const fadeFrom = ogHeight / 4
+		for y := fadeFrom; y < ogHeight; y++ {
+			a := uint8(235 * (y - fadeFrom) / (ogHeight - fadeFrom))
+			shade := image.NewUniform(color.NRGBA{0x11, 0x11, 0x11, a})
+			draw.Draw(canvas, image.Rect(0, y, ogWidth, y+1), shade, image.Point{}, draw.Over)
+		}
+	}
+
+	const margin = 64
+	white := image.NewUniform(color.White)
+	grey := image.NewUniform(color.RGBA{0xcc, 0xcc, 0xcc, 0xff})
+
+	// Branding: the site's triangle logo and name, bottom left.
+	brandY := ogHeight - margin
+	drawTriangle(canvas, margin, brandY-26, 28, color.White)
+	d := font.Drawer{Dst: canvas, Src: grey, Face: brandFace, Dot: fixed.P(margin+44, brandY)}
+	d.DrawString("Kohlschwarz Think-Tank")
+
+	// Title, up to three lines, bottom-aligned above the branding.
+	lines := wrapText(titleFace, app.Title, ogWidth-2*margin, 3)
+	lineHeight := titleFace.Metrics().Height.Ceil() + 8
+	y := brandY - 56 - (len(lines)-1)*lineHeight
+	d = font.Drawer{Dst: canvas, Src: white, Face: titleFace}
+	for _, line := range lines {
+		d.Dot = fixed.P(margin, y)
+		d.DrawString(line)
+		y += lineHeight
+	}
+	return canvas, nil
+}
+
+// loadThumbnail decodes the app's thumbnail if it is a file under
+// StaticDir. Remote thumbnails are not fetched.
+func (s *Server) loadThumbnail(app dbgen.App) image.Image {
+	if app.Thumbnail == nil {
+		return nil
+	}
+	rel, ok := strings.CutPrefix(*app.Thumbnail, "/static/")
+	if !ok {
+		return nil
+	}
+	f, err := os.Open(filepath.Join(s.StaticDir, filepath.FromSlash(filepath.Clean("/"+rel))))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		slog.Warn("decode thumbnail", "path", *app.Thumbnail, "error", err)
+		return nil
+	}
+	return img
+}
+
+// coverRect returns the centred part of src with the aspect ratio w:h, so
+// scaling it to w×h fills the target without distortion.
+func coverRect(src image.Rectangle, w, h int) image.Rectangle {
+	sw, sh := src.Dx(), src.Dy()
+	if sw*h > sh*w {
+		cw := sh * w / h
+		x := src.Min.X + (sw-cw)/2
+		return image.Rect(x, src.Min.Y, x+cw, src.Max.Y)
+	}
+	ch := sw * h / w
+	y := src.Min.Y + (sh-ch)/2
+	return image.Rect(src.Min.X, y, src.Max.X, y+ch)
+}
+
+// wrapText breaks text into at most maxLines lines no wider than width,
+// ending the last line with "…" if the text doesn't fit. A word too wide
+// for a line of its own is cut short with "…" as well.
+func wrapText(face font.Face, text string, width, maxLines int) []string {
+	fits := func(s string) bool { return font.MeasureString(face, s).Ceil() <= width }
+	var lines []string
+	line := ""
+	words := strings.Fields(text)
+	for i, word := range words {
+		if !fits(word) {
+			word = ellipsize(word, fits)
+		}
+		candidate := strings.TrimSpace(line + " " + word)
+		if fits(candidate) {
+			line = candidate
+			continue
+		}
+		if len(lines) == maxLines-1 {
+			rest := strings.Join(append([]string{line}, words[i:]...), " ")
+			return append(lines, ellipsize(rest, fits))
+		}
+		lines = append(lines, line)
+		line = word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// ellipsize shortens s until it fits with "…" appended, and appends it.
+func ellipsize(s string, fits func(string) bool) string {
+	for len(s) > 0 && !fits(s+"…") {
+		_, size := utf8.DecodeLastRuneInString(s)
+		s = strings.TrimSpace(s[:len(s)-size])
+	}
+	return s + "…"
+}
+
+// drawTriangle fills an upward triangle of the given size with its top-left
+// bounding corner at (x, y).
+func drawTriangle(dst draw.Image, x, y, size int, c color.Color) {
+	for row := 0; row < size; row++ {
+		half := row / 2
+		mid := x + size/2
+		for col := mid - half; col <= mid+half; col++ {
+			dst.Set(col, y+row, c)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package srv
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font"
+)
+
+func TestWrapText(t *testing.T) {
+	face, _, err := ogFonts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const width = 600
+	tests := []struct {
+		name     string
+		text     string
+		maxLines int
+		lines    int
+		ellipsis bool
+	}{
+		{"short", "Drought Risk Map", 2, 1, false},
+		{"wraps", "Groundwater levels meet hydropower", 3, 2, false},
+		{"too many lines", strings.Repeat("Holzeinschlag ", 12), 2, 2, true},
+		{"overlong word", "Donaudampfschifffahrtsgesellschaftskapitänsmütze und mehr", 3, 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := wrapText(face, tt.text, width, tt.maxLines)
+			if len(lines) != tt.lines {
+				t.Errorf("got %d lines %q, want %d", len(lines), lines, tt.lines)
+			}
+			for _, l := range lines {
+				if w := font.MeasureString(face, l).Ceil(); w > width {
+					t.Errorf("line %q is %d px wide, more than %d", l, w, width)
+				}
+			}
+			if got := strings.Contains(strings.Join(lines, "\n"), "…"); got != tt.ellipsis {
+				t.Errorf("ellipsis in %q: %v, want %v", lines, got, tt.ellipsis)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package srv
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"rsc.io/qr"
+
+	"srv.exe.dev/db/dbgen"
+)
+
+const (
+	// srcQR marks /go/{id} requests that come from a scanned QR code.
+	srcQR = "qr"
+
+	// qrQuietZone is the white border around a QR code, in modules; the
+	// standard asks for four.
+	qrQuietZone = 4
+
+	// qrPNGScale is the size of one QR module in the PNG, in pixels.
+	qrPNGScale = 10
+)
+
+// scanURL is what an app's QR code encodes: the /go redirect marked as a
+// scan, so scans are counted apart from card clicks.
+func scanURL(base string, appID int64) string {
+	return fmt.Sprintf("%s/go/%d?src=%s", base, appID, srcQR)
+}
+
+// appByIDParam loads the app named by the {id} path value, writing a 404
+// or 500 and returning false if there is none.
+func (s *Server) appByIDParam(w http.ResponseWriter, r *http.Request) (dbgen.App, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		s.notFound(w, r)
+		return dbgen.App{}, false
+	}
+	app, err := dbgen.New(s.DB).GetApp(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		s.notFound(w, r)
+		return dbgen.App{}, false
+	}
+	if err != nil {
+		slog.Warn("get app", "id", id, "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return dbgen.App{}, false
+	}
+	return app, true
+}
+
+// HandleQRSVG serves /apps/{id}/qr.svg.
+func (s *Server) HandleQRSVG(w http.ResponseWriter, r *http.Request) {
+	app, ok := s.appByIDParam(w, r)
+	if !ok {
+		return
+	}
+	code, err := qr.Encode(scanURL(s.baseURL(r), app.ID), qr.M)
+	if err != nil {
+		slog.Warn("encode qr", "id", app.ID, "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Write(qrSVG(code))
+}
+
+// HandleQRPNG serves /apps/{id}/qr.png.
+func (s *Server) HandleQRPNG(w http.ResponseWriter, r *http.Request) {
+	app, ok := s.appByIDParam(w, r)
+	if !ok {
+		return
+	}
+	code, err := qr.Encode(scanURL(s.baseURL(r), app.ID), qr.M)
+	if err != nil {
+		slog.Warn("encode qr", "id", app.ID, "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, qrImage(code, qrPNGScale)); err != nil {
+		slog.Warn("encode qr png", "id", app.ID, "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(buf.Bytes())
+}
+
+// qrSVG draws code as an SVG path, one unit per module, scaling to
+// whatever size it is shown at.
+func qrSVG(code *qr.Code) []byte {
+	size := code.Size + 2*qrQuietZone
+	var path strings.Builder
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			if code.Black(x, y) {
+				fmt.Fprintf(&path, "M%d %dh1v1h-1z", x+qrQuietZone, y+qrQuietZone)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %[1]d %[1]d" shape-rendering="crispEdges">`, size)
+	fmt.Fprintf(&buf, `<rect width="%[1]d" height="%[1]d" fill="#fff"/><path d="%s" fill="#000"/></svg>`, size, path.String())
+	return buf.Bytes()
+}
+
+// qrImage renders code with scale pixels per module and a quiet zone.
+func qrImage(code *qr.Code, scale int) image.Image {
+	size := (code.Size + 2*qrQuietZone) * scale
+	img := image.NewPaletted(image.Rect(0, 0, size, size), color.Palette{color.White, color.Black})
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if code.Black(x/scale-qrQuietZone, y/scale-qrQuietZone) {
+				img.SetColorIndex(x, y, 1)
+			}
+		}
+	}
+	return img
+}
+
+// countScan records a QR code scan of appID.
+func (s *Server) countScan(r *http.Request, appID int64) {
+	if err := dbgen.New(s.DB).CountQRScan(r.Context(), appID); err != nil {
+		slog.Warn("count qr scan", "id", appID, "error", err)
+	}
+}
+
+type posterPageData struct {
+	App     dbgen.App
+	ScanURL string
+}
+
+// HandlePoster renders a printable A4 handout for an app with its QR code.
+func (s *Server) HandlePoster(w http.ResponseWriter, r *http.Request) {
+	app, ok := s.appByIDParam(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	data := posterPageData{App: app, ScanURL: scanURL(s.baseURL(r), app.ID)}
+	if err := s.renderTemplate(w, r, "poster.html", data); err != nil {
+		slog.Warn("render template", "url", r.URL.Path, "error", err)
+	}
+}
@@ -0,0 +1,134 @@
+package srv
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"srv.exe.dev/db/dbgen"
+)
+
+// Base ranking modes for the homepage and /api/apps.
+const (
+	rankCurated = "curated" // sort_order as set in the admin form
+	rankPopular = "popular" // time-decayed clicks
+	rankNewest  = "newest"  // created_at, newest first
+)
+
+const (
+	// pinnedPrefix marks a ranking that puts pinned apps first, e.g.
+	// "pinned-popular".
+	pinnedPrefix = "pinned-"
+
+	// rankingSetting is the settings key holding the active ranking.
+	rankingSetting = "ranking"
+
+	defaultRanking = pinnedPrefix + rankPopular
+
+	// popularHalfLife is how long it takes a click to lose half its weight
+	// in the popular ranking. Only dated clicks from the last 90 days take
+	// part; the undated legacy click_count totals deliberately don't.
+	popularHalfLife = 7 * 24 * time.Hour
+)
+
+// rankings lists every valid ranking, in the order the admin form shows them.
+var rankings = []string{
+	rankCurated, rankPopular, rankNewest,
+	pinnedPrefix + rankCurated, pinnedPrefix + rankPopular, pinnedPrefix + rankNewest,
+}
+
+// ranking is a parsed ranking name.
+type ranking struct {
+	Mode        string
+	PinnedFirst bool
+}
+
+func parseRanking(name string) (ranking, error) {
+	if !slices.Contains(rankings, name) {
+		return ranking{}, fmt.Errorf("unknown ranking %q", name)
+	}
+	mode, pinned := strings.CutPrefix(name, pinnedPrefix)
+	return ranking{Mode: mode, PinnedFirst: pinned}, nil
+}
+
+func (rk ranking) String() string {
+	if rk.PinnedFirst {
+		return pinnedPrefix + rk.Mode
+	}
+	return rk.Mode
+}
+
+// activeRanking returns the ranking chosen in the admin, or defaultRanking.
+func (s *Server) activeRanking(ctx context.Context) ranking {
+	name, err := dbgen.New(s.DB).GetSetting(ctx, rankingSetting)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		slog.Warn("get ranking setting", "error", err)
+	}
+	rk, err := parseRanking(name)
+	if err != nil {
+		rk, _ = parseRanking(defaultRanking)
+	}
+	return rk
+}
+
+// rankApps sorts entries in place. Entries come in curated order from
+// ListApps, and every comparison falls back to that order on ties.
+func (s *Server) rankApps(ctx context.Context, entries []appEntry, rk ranking) error {
+	if rk.Mode == rankPopular {
+		decay := math.Ln2 / popularHalfLife.Hours()
+		scores, err := dbgen.New(s.DB).ListClickScores(ctx, decay)
+		if err != nil {
+			return fmt.Errorf("click scores: %w", err)
+		}
+		byApp := make(map[int64]float64, len(scores))
+		for _, sc := range scores {
+			byApp[sc.AppID] = sc.Score
+		}
+		for i := range entries {
+			entries[i].Score = byApp[entries[i].ID]
+		}
+	}
+
+	slices.SortStableFunc(entries, func(a, b appEntry) int {
+		if rk.PinnedFirst && a.Pinned != b.Pinned {
+			if a.Pinned {
+				return -1
+			}
+			return 1
+		}
+		switch rk.Mode {
+		case rankPopular:
+			return cmp.Compare(b.Score, a.Score)
+		case rankNewest:
+			return b.CreatedAt.Compare(a.CreatedAt)
+		}
+		return 0
+	})
+	return nil
+}
+
+func (s *Server) HandleAdminRanking(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.requireRole(w, r, roleEditor); !ok {
+		return
+	}
+
+	name := r.FormValue("ranking")
+	if _, err := parseRanking(name); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err := dbgen.New(s.DB).SetSetting(r.Context(), dbgen.SetSettingParams{Key: rankingSetting, Value: name})
+	if err != nil {
+		slog.Warn("set ranking", "error", err)
+	}
+
+	http.Redirect(w, r, "/admin", http.StatusSeeOther)
+}
@@ -0,0 +1,116 @@
+package srv
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"srv.exe.dev/db/dbgen"
+)
+
+// HandleGo counts a click on an app and redirects to it, so cards work
+// without JavaScript. Only ids of apps in the catalog are redirected; the
+// target always comes from the database, never from the request.
+//
+// Like the click beacon, only clicks on this site's pages and embedded
+// cards count towards the ranking: links to /go/{id} from elsewhere, and
+// scripts that don't send a Referer, are booked as cross-origin instead.
+func (s *Server) HandleGo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		s.notFound(w, r)
+		return
+	}
+	app, err := dbgen.New(s.DB).GetApp(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		s.notFound(w, r)
+		return
+	}
+	if err != nil {
+		slog.Warn("get app", "id", id, "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	// Rejected clicks still get redirected; they just aren't counted.
+	// Scans of the printed QR code carry no Referer. They are counted
+	// apart from card clicks and don't take part in the ranking, so a
+	// forged src=qr can't move it either.
+	scan := r.URL.Query().Get("src") == srcQR
+	reason := rejectCrossOrigin
+	if scan || sameOrigin(r) {
+		reason, _ = s.screenClick(ctx, r, app.ID)
+	}
+	switch {
+	case reason != "":
+		s.countRejection(ctx, reason)
+	case scan:
+		s.countScan(r, app.ID)
+	default:
+		s.recordClick(r, app.ID)
+	}
+
+	target := outboundURL(app)
+	if scan {
+		target = withQueryParam(target, "src", srcQR)
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// withQueryParam sets key=value on rawURL unless it already has key.
+func withQueryParam(rawURL, key, value string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	q := u.Query()
+	if q.Has(key) {
+		return rawURL
+	}
+	q.Set(key, value)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// outboundURL returns the app's URL with its UTM parameters appended.
+// Parameters already present in the stored URL win.
+func outboundURL(app dbgen.App) string {
+	u, err := url.Parse(app.Url)
+	if err != nil {
+		return app.Url
+	}
+	q := u.Query()
+	changed := false
+	for _, p := range []struct {
+		key string
+		val *string
+	}{
+		{"utm_source", app.UtmSource},
+		{"utm_medium", app.UtmMedium},
+		{"utm_campaign", app.UtmCampaign},
+	} {
+		if p.val == nil || *p.val == "" || q.Has(p.key) {
+			continue
+		}
+		q.Set(p.key, *p.val)
+		changed = true
+	}
+	if changed {
+		u.RawQuery = q.Encode()
+	}
+	return u.String()
+}
+
+// notFound renders the 404 page.
+func (s *Server) notFound(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusNotFound)
+	if err := s.renderTemplate(w, r, "notfound.html", nil); err != nil {
+		slog.Warn("render template", "url", r.URL.Path, "error", err)
+	}
+}
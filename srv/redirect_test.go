@@ -0,0 +1,72 @@
+package srv
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// popularOrder returns the app ids in popular order, flushing queued
+// clicks first.
+func popularOrder(t *testing.T, s *Server) []int64 {
+	t.Helper()
+	ctx := context.Background()
+	if err := s.flushClicks(ctx); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	entries, err := s.listApps(ctx, appQuery{Ranking: ranking{Mode: rankPopular}})
+	if err != nil {
+		t.Fatalf("listApps: %v", err)
+	}
+	ids := make([]int64, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ID
+	}
+	return ids
+}
+
+func TestGoClicksRanking(t *testing.T) {
+	s := newTestServer(t)
+	h := s.Handler()
+	before := popularOrder(t, s)
+	last := before[len(before)-1]
+	target := "/go/" + strconv.FormatInt(last, 10)
+
+	n := 0
+	goClick := func(query, ua, referer string) {
+		t.Helper()
+		n++
+		r := httptest.NewRequest(http.MethodGet, target+query, nil)
+		r.RemoteAddr = fmt.Sprintf("198.51.100.%d:4321", n)
+		r.Header.Set("User-Agent", ua)
+		if referer != "" {
+			r.Header.Set("Referer", referer)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != http.StatusFound {
+			t.Fatalf("%s: status %d, want %d", target+query, w.Code, http.StatusFound)
+		}
+	}
+
+	for i := range 20 {
+		ua := fmt.Sprintf("%s %d", browserUA, i)
+		goClick("?src=qr", ua, "")                        // forged scan
+		goClick("", ua, "")                               // script without Referer
+		goClick("", ua, "https://elsewhere.example/page") // link from another site
+		goClick("", "curl/8.5.0", "http://example.com/")  // repeated curl
+	}
+	after := popularOrder(t, s)
+	if fmt.Sprint(after) != fmt.Sprint(before) {
+		t.Fatalf("popular order moved from %v to %v", before, after)
+	}
+
+	// A click on a card of this site does count.
+	goClick("", browserUA, "http://example.com/")
+	if got := popularOrder(t, s); got[0] != last {
+		t.Errorf("after a same-origin click, app %d is not first: %v", last, got)
+	}
+}
@@ -0,0 +1,274 @@
+package srv
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"srv.exe.dev/db/dbgen"
+)
+
+// sdgGoal is one of the 17 UN Sustainable Development Goals.
+type sdgGoal struct {
+	Number int64
+	NameDE string
+	NameEN string
+	Color  string // official goal colour
+}
+
+// sdgGoals is the SDG taxonomy with the official German and English short
+// titles and colours, indexed by goal number - 1.
+var sdgGoals = []sdgGoal{
+	{1, "Keine Armut", "No Poverty", "#E5243B"},
+	{2, "Kein Hunger", "Zero Hunger", "#DDA63A"},
+	{3, "Gesundheit und Wohlergehen", "Good Health and Well-being", "#4C9F38"},
+	{4, "Hochwertige Bildung", "Quality Education", "#C5192D"},
+	{5, "Geschlechtergleichheit", "Gender Equality", "#FF3A21"},
+	{6, "Sauberes Wasser und Sanitäreinrichtungen", "Clean Water and Sanitation", "#26BDE2"},
+	{7, "Bezahlbare und saubere Energie", "Affordable and Clean Energy", "#FCC30B"},
+	{8, "Menschenwürdige Arbeit und Wirtschaftswachstum", "Decent Work and Economic Growth", "#A21942"},
+	{9, "Industrie, Innovation und Infrastruktur", "Industry, Innovation and Infrastructure", "#FD6925"},
+	{10, "Weniger Ungleichheiten", "Reduced Inequalities", "#DD1367"},
+	{11, "Nachhaltige Städte und Gemeinden", "Sustainable Cities and Communities", "#FD9D24"},
+	{12, "Nachhaltige/r Konsum und Produktion", "Responsible Consumption and Production", "#BF8B2E"},
+	{13, "Maßnahmen zum Klimaschutz", "Climate Action", "#3F7E44"},
+	{14, "Leben unter Wasser", "Life Below Water", "#0A97D9"},
+	{15, "Leben an Land", "Life on Land", "#56C02B"},
+	{16, "Frieden, Gerechtigkeit und starke Institutionen", "Peace, Justice and Strong Institutions", "#00689D"},
+	{17, "Partnerschaften zur Erreichung der Ziele", "Partnerships for the Goals", "#19486A"},
+}
+
+// sdgByNumber returns goal n, or false if n is not a goal number.
+func sdgByNumber(n int64) (sdgGoal, bool) {
+	if n < 1 || n > int64(len(sdgGoals)) {
+		return sdgGoal{}, false
+	}
+	return sdgGoals[n-1], true
+}
+
+// URL is the goal's page on the UN SDG site.
+func (g sdgGoal) URL() string {
+	return fmt.Sprintf("https://sdgs.un.org/goals/goal%d", g.Number)
+}
+
+// sdgIconDir is where the UN goal icons go, below the static directory,
+// as goal-01.png to goal-17.png (or .svg). They are not shipped with the
+// site; see the README there.
+const sdgIconDir = "sdg"
+
+// loadSDGIcons returns the URLs of the goal icons installed below
+// staticDir, by goal number.
+func loadSDGIcons(staticDir string) map[int64]string {
+	icons := make(map[int64]string)
+	for _, g := range sdgGoals {
+		for _, ext := range []string{".svg", ".png"} {
+			name := fmt.Sprintf("goal-%02d%s", g.Number, ext)
+			if _, err := os.Stat(filepath.Join(staticDir, sdgIconDir, name)); err == nil {
+				icons[g.Number] = "/static/" + sdgIconDir + "/" + name
+				break
+			}
+		}
+	}
+	if n := len(icons); n > 0 && n < len(sdgGoals) {
+		slog.Warn("some SDG icons missing, drawing tiles for those", "found", n, "dir", filepath.Join(staticDir, sdgIconDir))
+	}
+	return icons
+}
+
+// sdgIcon renders goal n as a square tile with its number in the goal
+// colour or, if the UN icon is installed, as that icon. Templates call it
+// as {{sdgIcon .Number 16}}.
+func (s *Server) sdgIcon(n int64, size int) template.HTML {
+	g, ok := sdgByNumber(n)
+	if !ok {
+		return ""
+	}
+	if src, ok := s.sdgIcons[g.Number]; ok {
+		return template.HTML(fmt.Sprintf(
+			`<img class="sdg-icon" src="%s" width="%d" height="%d" alt="SDG %d: %s" title="SDG %d: %s / %s">`,
+			src, size, size, g.Number, template.HTMLEscapeString(g.NameDE),
+			g.Number, template.HTMLEscapeString(g.NameDE), template.HTMLEscapeString(g.NameEN)))
+	}
+	return template.HTML(fmt.Sprintf(
+		`<svg class="sdg-icon" viewBox="0 0 40 40" width="%d" height="%d" role="img" aria-label="SDG %d: %s"><title>SDG %d: %s / %s</title><rect width="40" height="40" fill="%s"/><text x="20" y="27" text-anchor="middle" font-size="20" font-weight="700" fill="#fff" font-family="sans-serif">%d</text></svg>`,
+		size, size, g.Number, template.HTMLEscapeString(g.NameDE),
+		g.Number, template.HTMLEscapeString(g.NameDE), template.HTMLEscapeString(g.NameEN),
+		g.Color, g.Number))
+}
+
+// sdgTerm is a goal as a schema.org DefinedTerm, for JSON-LD "about".
+type sdgTerm struct {
+	Type             string `json:"@type"`
+	TermCode         string `json:"termCode"`
+	Name             string `json:"name"`
+	URL              string `json:"url"`
+	InDefinedTermSet string `json:"inDefinedTermSet"`
+}
+
+func (g sdgGoal) term() sdgTerm {
+	return sdgTerm{
+		Type:             "DefinedTerm",
+		TermCode:         fmt.Sprintf("SDG%d", g.Number),
+		Name:             fmt.Sprintf("SDG %d: %s", g.Number, g.NameEN),
+		URL:              g.URL(),
+		InDefinedTermSet: "https://sdgs.un.org/goals",
+	}
+}
+
+// Goals returns the SDGs the app is linked to.
+func (e appEntry) Goals() []sdgGoal {
+	var goals []sdgGoal
+	for _, n := range e.SDGs {
+		if g, ok := sdgByNumber(n); ok {
+			goals = append(goals, g)
+		}
+	}
+	return goals
+}
+
+// sdgChoice is a goal offered as a filter chip or form checkbox.
+type sdgChoice struct {
+	sdgGoal
+	Selected bool
+}
+
+// sdgChoices returns the goals at least one app is linked to, marking
+// selected as chosen.
+func (s *Server) sdgChoices(ctx context.Context, selected int64) []sdgChoice {
+	rows, err := dbgen.New(s.DB).ListAppSDGs(ctx)
+	if err != nil {
+		slog.Warn("list app sdgs", "error", err)
+		return nil
+	}
+	var choices []sdgChoice
+	for _, row := range rows {
+		if len(choices) > 0 && choices[len(choices)-1].Number == row.Goal {
+			continue
+		}
+		if g, ok := sdgByNumber(row.Goal); ok {
+			choices = append(choices, sdgChoice{sdgGoal: g, Selected: g.Number == selected})
+		}
+	}
+	return choices
+}
+
+// allSDGChoices returns all 17 goals, marking those in selected.
+func allSDGChoices(selected []int64) []sdgChoice {
+	choices := make([]sdgChoice, len(sdgGoals))
+	for i, g := range sdgGoals {
+		choices[i] = sdgChoice{sdgGoal: g, Selected: slices.Contains(selected, g.Number)}
+	}
+	return choices
+}
+
+// sdgsInUse returns the goals any of apps is linked to, in goal order.
+func sdgsInUse(apps []appEntry) []sdgGoal {
+	var numbers []int64
+	for _, app := range apps {
+		for _, n := range app.SDGs {
+			if !slices.Contains(numbers, n) {
+				numbers = append(numbers, n)
+			}
+		}
+	}
+	slices.Sort(numbers)
+	var goals []sdgGoal
+	for _, n := range numbers {
+		if g, ok := sdgByNumber(n); ok {
+			goals = append(goals, g)
+		}
+	}
+	return goals
+}
+
+// sdgTerms converts goals to JSON-LD terms.
+func sdgTerms(goals []sdgGoal) []sdgTerm {
+	terms := make([]sdgTerm, len(goals))
+	for i, g := range goals {
+		terms[i] = g.term()
+	}
+	return terms
+}
+
+// sdgParam reads ?sdg= from r; it returns 0 if absent or not a goal.
+func sdgParam(r *http.Request) int64 {
+	n, err := strconv.ParseInt(r.URL.Query().Get("sdg"), 10, 64)
+	if _, ok := sdgByNumber(n); err != nil || !ok {
+		return 0
+	}
+	return n
+}
+
+// parseSDGs reads the goal checkboxes of the edit form.
+func parseSDGs(values []string) []int64 {
+	var goals []int64
+	for _, v := range values {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if _, ok := sdgByNumber(n); err == nil && ok && !slices.Contains(goals, n) {
+			goals = append(goals, n)
+		}
+	}
+	return goals
+}
+
+// setAppSDGs replaces the goals an app is linked to.
+func (s *Server) setAppSDGs(ctx context.Context, appID int64, goals []int64) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := dbgen.New(tx)
+	if err := q.DeleteAppSDGs(ctx, appID); err != nil {
+		return err
+	}
+	for _, g := range goals {
+		if err := q.AddAppSDG(ctx, dbgen.AddAppSDGParams{AppID: appID, Goal: g}); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// sdgSection is one goal on the /sdg overview with the apps linked to it.
+type sdgSection struct {
+	Goal sdgGoal
+	Apps []appEntry
+}
+
+type sdgPageData struct {
+	Hostname string
+	Sections []sdgSection
+}
+
+// HandleSDG lists all 17 goals with the apps addressing each.
+func (s *Server) HandleSDG(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	apps, err := s.listApps(ctx, appQuery{Ranking: s.activeRanking(ctx)})
+	if err != nil {
+		slog.Warn("list apps", "error", err)
+	}
+
+	data := sdgPageData{Hostname: s.Hostname}
+	for _, g := range sdgGoals {
+		section := sdgSection{Goal: g}
+		for _, app := range apps {
+			if slices.Contains(app.SDGs, g.Number) {
+				section.Apps = append(section.Apps, app)
+			}
+		}
+		data.Sections = append(data.Sections, section)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.renderTemplate(w, r, "sdg.html", data); err != nil {
+		slog.Warn("render template", "url", r.URL.Path, "error", err)
+	}
+}
@@ -0,0 +1,36 @@
+package srv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSDGIconsPerServer checks that installed UN icons are a setting of
+// each Server, not shared between them.
+func TestSDGIconsPerServer(t *testing.T) {
+	withIcon := newTestServer(t)
+	withIcon.StaticDir = t.TempDir()
+	if err := os.MkdirAll(filepath.Join(withIcon.StaticDir, sdgIconDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(withIcon.StaticDir, sdgIconDir, "goal-01.svg"), []byte("<svg/>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	withIcon.sdgIcons = loadSDGIcons(withIcon.StaticDir)
+	plain := newTestServer(t)
+
+	if got := withIcon.sdgIcon(1, 16); !strings.Contains(string(got), `src="/static/sdg/goal-01.svg"`) {
+		t.Errorf("goal 1 with icon installed: %s", got)
+	}
+	if got := withIcon.sdgIcon(2, 16); !strings.HasPrefix(string(got), "<svg") {
+		t.Errorf("goal 2 without icon is not a tile: %s", got)
+	}
+	if got := plain.sdgIcon(1, 16); !strings.HasPrefix(string(got), "<svg") {
+		t.Errorf("goal 1 on the other server is not a tile: %s", got)
+	}
+	if got := plain.sdgIcon(18, 16); got != "" {
+		t.Errorf("goal 18 = %s, want nothing", got)
+	}
+}
@@ -0,0 +1,35 @@
+package srv
+
+import (
+	"strings"
+	"unicode"
+)
+
+// germanFold spells out umlauts and ß the same way migration 010 does for
+// the indexed text, so "Dürre", "Duerre" and "DÜRRE" all match.
+var germanFold = strings.NewReplacer(
+	"ä", "ae", "ö", "oe", "ü", "ue",
+	"Ä", "Ae", "Ö", "Oe", "Ü", "Ue",
+	"ß", "ss", "ẞ", "SS",
+)
+
+// maxSearchTerms caps how many words of a query are used.
+const maxSearchTerms = 8
+
+// searchQuery turns free text typed by a visitor into an FTS5 match
+// expression: every word becomes a quoted prefix term and all of them must
+// match. FTS5 operators in the input are treated as plain words. It
+// returns "" if the input contains no words.
+func searchQuery(input string) string {
+	words := strings.FieldsFunc(germanFold.Replace(input), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > maxSearchTerms {
+		words = words[:maxSearchTerms]
+	}
+	terms := make([]string, len(words))
+	for i, w := range words {
+		terms[i] = `"` + w + `"*`
+	}
+	return strings.Join(terms, " ")
+}
@@ -0,0 +1,170 @@
+package srv
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"srv.exe.dev/db/dbgen"
+)
+
+const (
+	sitemapNS      = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	sitemapImageNS = "http://www.google.com/schemas/sitemap-image/1.1"
+)
+
+// maxSitemapURLs is the most URLs one sitemap file may hold under the
+// sitemaps.org protocol. Beyond that, /sitemap.xml becomes an index of
+// /sitemaps/N.xml parts. It is a variable so tests can split a small
+// sitemap.
+var maxSitemapURLs = 50000
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	NS      string       `xml:"xmlns,attr"`
+	ImageNS string       `xml:"xmlns:image,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc        string         `xml:"loc"`
+	LastMod    string         `xml:"lastmod,omitempty"`
+	ChangeFreq string         `xml:"changefreq,omitempty"`
+	Priority   string         `xml:"priority,omitempty"`
+	Images     []sitemapImage `xml:"image:image"`
+}
+
+type sitemapImage struct {
+	Loc string `xml:"image:loc"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name       `xml:"sitemapindex"`
+	NS       string         `xml:"xmlns,attr"`
+	Sitemaps []sitemapEntry `xml:"sitemap"`
+}
+
+type sitemapEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// sitemapURLs lists every public page: the homepage, the legal pages and
+// one detail page per app.
+func (s *Server) sitemapURLs(r *http.Request) ([]sitemapURL, error) {
+	apps, err := dbgen.New(s.DB).ListApps(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	base := s.baseURL(r)
+
+	var newest time.Time
+	for _, app := range apps {
+		if app.UpdatedAt.After(newest) {
+			newest = app.UpdatedAt
+		}
+	}
+	urls := []sitemapURL{
+		{Loc: base + "/", LastMod: lastMod(newest), ChangeFreq: "weekly", Priority: "1.0"},
+		{Loc: base + "/impressum", ChangeFreq: "monthly", Priority: "0.3"},
+		{Loc: base + "/datenschutz", ChangeFreq: "monthly", Priority: "0.3"},
+	}
+	for _, app := range apps {
+		if app.Slug == "" {
+			continue
+		}
+		u := sitemapURL{
+			Loc:        base + "/apps/" + app.Slug,
+			LastMod:    lastMod(app.UpdatedAt),
+			ChangeFreq: "monthly",
+			Priority:   "0.8",
+		}
+		u.Images = []sitemapImage{{Loc: ogImageURL(base, app)}}
+		urls = append(urls, u)
+	}
+	return urls, nil
+}
+
+// lastMod formats t as a W3C datetime; the zero time gives "".
+func lastMod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// maxLastMod returns the latest lastmod of urls.
+func maxLastMod(urls []sitemapURL) string {
+	var latest string
+	for _, u := range urls {
+		// RFC 3339 timestamps in UTC sort lexically.
+		if u.LastMod > latest {
+			latest = u.LastMod
+		}
+	}
+	return latest
+}
+
+// HandleSitemap serves the sitemap, or a sitemap index once there are more
+// than maxSitemapURLs pages.
+func (s *Server) HandleSitemap(w http.ResponseWriter, r *http.Request) {
+	urls, err := s.sitemapURLs(r)
+	if err != nil {
+		slog.Warn("sitemap", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	if len(urls) <= maxSitemapURLs {
+		writeXML(w, sitemapURLSet{NS: sitemapNS, ImageNS: sitemapImageNS, URLs: urls})
+		return
+	}
+
+	base := s.baseURL(r)
+	index := sitemapIndex{NS: sitemapNS}
+	for part, start := 1, 0; start < len(urls); part, start = part+1, start+maxSitemapURLs {
+		end := min(start+maxSitemapURLs, len(urls))
+		index.Sitemaps = append(index.Sitemaps, sitemapEntry{
+			Loc:     fmt.Sprintf("%s/sitemaps/%d.xml", base, part),
+			LastMod: maxLastMod(urls[start:end]),
+		})
+	}
+	writeXML(w, index)
+}
+
+// HandleSitemapPart serves /sitemaps/N.xml, the Nth slice of the sitemap
+// listed in the index.
+func (s *Server) HandleSitemapPart(w http.ResponseWriter, r *http.Request) {
+	num, ok := strings.CutSuffix(r.PathValue("name"), ".xml")
+	part, err := strconv.Atoi(num)
+	if !ok || err != nil || part < 1 {
+		s.notFound(w, r)
+		return
+	}
+	urls, err := s.sitemapURLs(r)
+	if err != nil {
+		slog.Warn("sitemap", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	start := (part - 1) * maxSitemapURLs
+	if start >= len(urls) {
+		s.notFound(w, r)
+		return
+	}
+	end := min(start+maxSitemapURLs, len(urls))
+	writeXML(w, sitemapURLSet{NS: sitemapNS, ImageNS: sitemapImageNS, URLs: urls[start:end]})
+}
+
+func writeXML(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Write([]byte(xml.Header))
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		slog.Warn("encode xml", "error", err)
+	}
+}
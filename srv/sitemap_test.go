@@ -0,0 +1,102 @@
+package srv
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// getXML fetches path and decodes the XML response into v.
+func getXML(t *testing.T, h http.Handler, path string, v any) int {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	if w.Code != http.StatusOK {
+		return w.Code
+	}
+	if err := xml.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("%s is not valid XML: %v", path, err)
+	}
+	return w.Code
+}
+
+func TestSitemapIndex(t *testing.T) {
+	s := newTestServer(t)
+	h := s.Handler()
+	all, err := s.sitemapURLs(httptest.NewRequest(http.MethodGet, "/sitemap.xml", nil))
+	if err != nil {
+		t.Fatalf("sitemapURLs: %v", err)
+	}
+
+	defer func(n int) { maxSitemapURLs = n }(maxSitemapURLs)
+	maxSitemapURLs = 2
+	wantParts := (len(all) + maxSitemapURLs - 1) / maxSitemapURLs
+	if wantParts < 2 {
+		t.Fatalf("only %d sitemap URLs, too few to split", len(all))
+	}
+
+	var index sitemapIndex
+	if code := getXML(t, h, "/sitemap.xml", &index); code != http.StatusOK {
+		t.Fatalf("/sitemap.xml: status %d", code)
+	}
+	if index.XMLName.Local != "sitemapindex" {
+		t.Fatalf("/sitemap.xml root is <%s>, want <sitemapindex>", index.XMLName.Local)
+	}
+	if len(index.Sitemaps) != wantParts {
+		t.Fatalf("index lists %d parts, want %d", len(index.Sitemaps), wantParts)
+	}
+
+	seen := make(map[string]bool)
+	for _, part := range index.Sitemaps {
+		path, ok := strings.CutPrefix(part.Loc, "http://example.com")
+		if !ok || !strings.HasPrefix(path, "/sitemaps/") {
+			t.Fatalf("index entry %q is not a /sitemaps/ URL", part.Loc)
+		}
+		var set sitemapURLSet
+		if code := getXML(t, h, path, &set); code != http.StatusOK {
+			t.Fatalf("%s: status %d", path, code)
+		}
+		if set.XMLName.Local != "urlset" || len(set.URLs) == 0 || len(set.URLs) > maxSitemapURLs {
+			t.Errorf("%s: <%s> with %d URLs, want <urlset> with 1 to %d", path, set.XMLName.Local, len(set.URLs), maxSitemapURLs)
+		}
+		for _, u := range set.URLs {
+			if seen[u.Loc] {
+				t.Errorf("%s listed twice", u.Loc)
+			}
+			seen[u.Loc] = true
+		}
+	}
+	for _, u := range all {
+		if !seen[u.Loc] {
+			t.Errorf("%s is in no sitemap part", u.Loc)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/sitemaps/"+strconv.Itoa(wantParts+1)+".xml", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("part after the last: status %d, want 404", w.Code)
+	}
+}
+
+func TestSitemapEscaping(t *testing.T) {
+	const loc = "http://example.com/apps/x?a=1&b=<2>"
+	w := httptest.NewRecorder()
+	writeXML(w, sitemapURLSet{NS: sitemapNS, ImageNS: sitemapImageNS, URLs: []sitemapURL{
+		{Loc: loc, Images: []sitemapImage{{Loc: loc}}},
+	}})
+	body := w.Body.String()
+	if strings.Contains(body, "a=1&b") || !strings.Contains(body, "a=1&amp;b=&lt;2&gt;") {
+		t.Fatalf("URL not escaped:\n%s", body)
+	}
+	var set sitemapURLSet
+	if err := xml.Unmarshal(w.Body.Bytes(), &set); err != nil {
+		t.Fatalf("invalid XML: %v", err)
+	}
+	if len(set.URLs) != 1 || set.URLs[0].Loc != loc {
+		t.Errorf("decoded %+v, want loc %q", set.URLs, loc)
+	}
+}
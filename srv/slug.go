@@ -0,0 +1,45 @@
+package srv
+
+import (
+	"strings"
+	"unicode"
+)
+
+// slugFold transliterates German and other common Latin letters to ASCII
+// before slugifying; umlauts are spelled out as in German (ä -> ae).
+var slugFold = strings.NewReplacer(
+	"ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss",
+	"á", "a", "à", "a", "â", "a", "å", "a", "ã", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ø", "o",
+	"ú", "u", "ù", "u", "û", "u",
+	"ç", "c", "č", "c", "ć", "c", "š", "s", "ž", "z", "ñ", "n",
+	"€", "eur", "&", "und",
+)
+
+// maxSlugLen caps generated slugs so URLs stay readable.
+const maxSlugLen = 60
+
+// slugify turns a title into a lowercase ASCII slug such as
+// "schulqualitaet-oesterreich".
+func slugify(s string) string {
+	s = slugFold.Replace(strings.ToLower(s))
+	var b strings.Builder
+	dash := false
+	for _, r := range s {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+			dash = false
+		case !dash && b.Len() > 0:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	slug := strings.TrimSuffix(b.String(), "-")
+	if len(slug) > maxSlugLen {
+		slug = strings.TrimSuffix(slug[:maxSlugLen], "-")
+	}
+	return slug
+}
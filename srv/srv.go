@@ -4,15 +4,19 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"srv.exe.dev/db"
 	"srv.exe.dev/db/dbgen"
@@ -23,36 +27,99 @@ type Server struct {
 	Hostname     string
 	TemplatesDir string
 	StaticDir    string
+
+	// ClickFlushInterval is how often queued clicks are written out.
+	ClickFlushInterval time.Duration
+
+	// BaseURL is the canonical origin, e.g. "https://kohlschwarz.at:8000",
+	// used for absolute URLs. Requests for other hosts are redirected to
+	// it. If empty, the request's own host is used.
+	BaseURL string
+
+	// CacheDir holds generated files such as Open Graph images.
+	CacheDir string
+
+	// ExeDev lets allowlisted exe.dev users in without a password.
+	ExeDev ExeDevAuth
+
+	// Dev relaxes production safeguards for local development: the server
+	// starts without an admin user and session cookies work over HTTP.
+	Dev bool
+
+	salt    dailySalt
+	clicks  clickGuard
+	embeds  clickGuard
+	buffer  clickBuffer
+	og      ogCache
+	catalog catalogCache
+	csrfKey []byte
+
+	ignoredHeaders ignoredHeaders // exe.dev headers from untrusted addresses
+
+	// sdgIcons maps goal numbers to the URLs of the UN icons installed
+	// below StaticDir; see loadSDGIcons.
+	sdgIcons map[int64]string
 }
 
 type pageData struct {
 	Hostname string
-	Apps     []dbgen.App
+	Apps     []appEntry
+	Ranking  string
+	Rankings []string
+	Query    string
+	Tags     []tagFacet
+	Tag      *dbgen.Tag
+	TagNames string
+	Goals    []sdgChoice
+	Goal     *sdgGoal
+	About    []sdgTerm // SDGs of the listed apps, for JSON-LD
+	AppList  appItemList
+	Share    *dbgen.App // app named by ?app=, whose image the share tags use
 	App      *dbgen.App
 	Error    string
 	Success  string
+	User     string // signed-in admin
+	Role     role
+	ExeDev   bool // User signed in through exe.dev
+
+	// CatalogPublic reports whether /catalog.pdf is open to everyone.
+	CatalogPublic bool
 }
 
 func New(dbPath, hostname string) (*Server, error) {
 	_, thisFile, _, _ := runtime.Caller(0)
 	baseDir := filepath.Dir(thisFile)
 	srv := &Server{
-		Hostname:     hostname,
-		TemplatesDir: filepath.Join(baseDir, "templates"),
-		StaticDir:    filepath.Join(baseDir, "static"),
+		Hostname:           hostname,
+		TemplatesDir:       filepath.Join(baseDir, "templates"),
+		StaticDir:          filepath.Join(baseDir, "static"),
+		ClickFlushInterval: DefaultClickFlushInterval,
+		CacheDir:           "cache",
 	}
 	if err := srv.setUpDatabase(dbPath); err != nil {
 		return nil, err
 	}
+	srv.sdgIcons = loadSDGIcons(srv.StaticDir)
+	if err := srv.loadCSRFKey(context.Background()); err != nil {
+		return nil, err
+	}
 	if err := srv.seedApps(); err != nil {
 		slog.Warn("seed apps", "error", err)
 	}
+	if err := srv.fillSlugs(context.Background()); err != nil {
+		slog.Warn("fill app slugs", "error", err)
+	}
 	return srv, nil
 }
 
 func (s *Server) HandleRoot(w http.ResponseWriter, r *http.Request) {
-	q := dbgen.New(s.DB)
-	apps, err := q.ListApps(r.Context())
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	sdg := sdgParam(r)
+	apps, err := s.listApps(r.Context(), appQuery{
+		Ranking: s.activeRanking(r.Context()),
+		Search:  query,
+		SDG:     sdg,
+	})
 	if err != nil {
 		slog.Warn("list apps", "error", err)
 	}
@@ -60,52 +127,58 @@ func (s *Server) HandleRoot(w http.ResponseWriter, r *http.Request) {
 	data := pageData{
 		Hostname: s.Hostname,
 		Apps:     apps,
+		Query:    query,
+		Tags:     s.allTagFacets(r.Context()),
+		Goals:    s.sdgChoices(r.Context(), sdg),
+		About:    sdgTerms(sdgsInUse(apps)),
+		AppList:  appListJSONLD(s.baseURL(r), apps),
+	}
+	if g, ok := sdgByNumber(sdg); ok {
+		data.Goal = &g
+	}
+	if id, err := strconv.ParseInt(r.URL.Query().Get("app"), 10, 64); err == nil && id > 0 {
+		if app, err := dbgen.New(s.DB).GetApp(r.Context(), id); err == nil {
+			data.Share = &app
+		}
 	}
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	if err := s.renderTemplate(w, "index.html", data); err != nil {
+	if err := s.renderTemplate(w, r, "index.html", data); err != nil {
 		slog.Warn("render template", "url", r.URL.Path, "error", err)
 	}
 }
 
-func (s *Server) requireAuth(w http.ResponseWriter, r *http.Request) bool {
-	adminPassword := os.Getenv("ADMIN_PASSWORD")
-	if adminPassword == "" {
-		adminPassword = "changeme" // fallback for local dev
-	}
-	user, pass, ok := r.BasicAuth()
-	if !ok || user != "admin" || pass != adminPassword {
-		w.Header().Set("WWW-Authenticate", `Basic realm="Admin"`)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return false
-	}
-	return true
-}
-
 func (s *Server) HandleAdmin(w http.ResponseWriter, r *http.Request) {
-	if !s.requireAuth(w, r) {
+	user, ok := s.requireRole(w, r, roleViewer)
+	if !ok {
 		return
 	}
 
-	q := dbgen.New(s.DB)
-	apps, err := q.ListApps(r.Context())
+	rk := s.activeRanking(r.Context())
+	apps, err := s.listApps(r.Context(), appQuery{Ranking: rk})
 	if err != nil {
 		slog.Warn("list apps", "error", err)
 	}
 
 	data := pageData{
-		Hostname: s.Hostname,
-		Apps:     apps,
+		Hostname:      s.Hostname,
+		Apps:          apps,
+		Ranking:       rk.String(),
+		Rankings:      rankings,
+		CatalogPublic: s.catalogPublic(r.Context()),
+		User:          user.Username,
+		Role:          role(user.Role),
+		ExeDev:        user.ExeDev,
 	}
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	if err := s.renderTemplate(w, "admin.html", data); err != nil {
+	if err := s.renderTemplate(w, r, "admin.html", data); err != nil {
 		slog.Warn("render template", "url", r.URL.Path, "error", err)
 	}
 }
 
 func (s *Server) HandleAdminEdit(w http.ResponseWriter, r *http.Request) {
-	if !s.requireAuth(w, r) {
+	if _, ok := s.requireRole(w, r, roleEditor); !ok {
 		return
 	}
 
@@ -113,7 +186,7 @@ func (s *Server) HandleAdminEdit(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.ParseInt(idStr, 10, 64)
 
 	q := dbgen.New(s.DB)
-	data := pageData{Hostname: s.Hostname}
+	data := pageData{Hostname: s.Hostname, Tags: s.allTagFacets(r.Context()), Goals: allSDGChoices(nil)}
 
 	if id > 0 {
 		app, err := q.GetApp(r.Context(), id)
@@ -121,17 +194,31 @@ func (s *Server) HandleAdminEdit(w http.ResponseWriter, r *http.Request) {
 			data.Error = "App not found"
 		} else {
 			data.App = &app
+			tags, err := q.ListTagsForApp(r.Context(), id)
+			if err != nil {
+				slog.Warn("list app tags", "error", err)
+			}
+			names := make([]string, len(tags))
+			for i, t := range tags {
+				names[i] = t.Name
+			}
+			data.TagNames = strings.Join(names, ", ")
+			goals, err := q.ListSDGsForApp(r.Context(), id)
+			if err != nil {
+				slog.Warn("list app sdgs", "error", err)
+			}
+			data.Goals = allSDGChoices(goals)
 		}
 	}
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	if err := s.renderTemplate(w, "edit.html", data); err != nil {
+	if err := s.renderTemplate(w, r, "edit.html", data); err != nil {
 		slog.Warn("render template", "url", r.URL.Path, "error", err)
 	}
 }
 
 func (s *Server) HandleAdminSave(w http.ResponseWriter, r *http.Request) {
-	if !s.requireAuth(w, r) {
+	if _, ok := s.requireRole(w, r, roleEditor); !ok {
 		return
 	}
 
@@ -149,39 +236,78 @@ func (s *Server) HandleAdminSave(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	prompt := r.FormValue("prompt")
+	utmSource := r.FormValue("utm_source")
+	utmMedium := r.FormValue("utm_medium")
+	utmCampaign := r.FormValue("utm_campaign")
+	pinned := r.FormValue("pinned") != ""
+	authors := strings.TrimSpace(strings.ReplaceAll(r.FormValue("authors"), "\r\n", "\n"))
+	version := strings.TrimSpace(r.FormValue("version"))
+	doi := normalizeDOI(r.FormValue("doi"))
+	slug, err := uniqueSlug(ctx, q, r.FormValue("slug"), title, id)
+	if err != nil {
+		slog.Warn("app slug", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
 
 	if id > 0 {
 		err := q.UpdateApp(ctx, dbgen.UpdateAppParams{
 			ID:          id,
 			Url:         url,
 			Title:       title,
+			Slug:        slug,
 			Description: description,
 			Thumbnail:   &thumbnail,
 			SortOrder:   &sortOrder,
 			Prompt:      &prompt,
+			UtmSource:   &utmSource,
+			UtmMedium:   &utmMedium,
+			UtmCampaign: &utmCampaign,
+			Pinned:      pinned,
+			Authors:     authors,
+			Version:     version,
+			Doi:         doi,
 		})
 		if err != nil {
 			slog.Warn("update app", "error", err)
 		}
 	} else {
-		_, err := q.CreateApp(ctx, dbgen.CreateAppParams{
+		app, err := q.CreateApp(ctx, dbgen.CreateAppParams{
 			Url:         url,
 			Title:       title,
+			Slug:        slug,
 			Description: description,
 			Thumbnail:   &thumbnail,
 			SortOrder:   &sortOrder,
 			Prompt:      &prompt,
+			UtmSource:   &utmSource,
+			UtmMedium:   &utmMedium,
+			UtmCampaign: &utmCampaign,
+			Pinned:      pinned,
+			Authors:     authors,
+			Version:     version,
+			Doi:         doi,
 		})
 		if err != nil {
 			slog.Warn("create app", "error", err)
 		}
+		id = app.ID
+	}
+
+	if id > 0 {
+		if err := s.setAppTags(ctx, id, parseTagNames(r.FormValue("tags"))); err != nil {
+			slog.Warn("set app tags", "error", err)
+		}
+		if err := s.setAppSDGs(ctx, id, parseSDGs(r.Form["sdg"])); err != nil {
+			slog.Warn("set app sdgs", "error", err)
+		}
 	}
 
 	http.Redirect(w, r, "/admin", http.StatusSeeOther)
 }
 
 func (s *Server) HandleAdminDelete(w http.ResponseWriter, r *http.Request) {
-	if !s.requireAuth(w, r) {
+	if _, ok := s.requireRole(w, r, roleOwner); !ok {
 		return
 	}
 
@@ -198,15 +324,48 @@ func (s *Server) HandleAdminDelete(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/admin", http.StatusSeeOther)
 }
 
+// apiAppsResponse is the body of /api/apps?facets=1. Facets count the tags
+// of the returned apps. Without the parameter the body is just the array
+// of apps, as it always was.
+type apiAppsResponse struct {
+	Apps   []appEntry `json:"apps"`
+	Facets apiFacets  `json:"facets"`
+}
+
+type apiFacets struct {
+	Tags []tagFacet `json:"tags"`
+}
+
 func (s *Server) HandleAPIApps(w http.ResponseWriter, r *http.Request) {
-	q := dbgen.New(s.DB)
-	apps, err := q.ListApps(r.Context())
+	aq := appQuery{
+		Ranking:     s.activeRanking(r.Context()),
+		Search:      strings.TrimSpace(r.URL.Query().Get("q")),
+		Tags:        tagParams(r),
+		MatchAnyTag: r.URL.Query().Get("match") == "any",
+		SDG:         sdgParam(r),
+	}
+	if sort := r.URL.Query().Get("sort"); sort != "" {
+		var err error
+		if aq.Ranking, err = parseRanking(sort); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		aq.ByRanking = true
+	}
+	apps, err := s.listApps(r.Context(), aq)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(apps)
+	if r.URL.Query().Get("facets") != "1" {
+		json.NewEncoder(w).Encode(apps)
+		return
+	}
+	json.NewEncoder(w).Encode(apiAppsResponse{
+		Apps:   apps,
+		Facets: apiFacets{Tags: facetCounts(apps)},
+	})
 }
 
 func (s *Server) HandleTrackClick(w http.ResponseWriter, r *http.Request) {
@@ -217,18 +376,54 @@ func (s *Server) HandleTrackClick(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	q := dbgen.New(s.DB)
-	if err := q.IncrementClickCount(r.Context(), id); err != nil {
-		slog.Warn("increment click", "error", err)
+	ctx := r.Context()
+	if _, err := dbgen.New(s.DB).GetApp(ctx, id); errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "unknown app", http.StatusNotFound)
+		return
+	} else if err != nil {
+		slog.Warn("get app", "id", id, "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	reason, wait := rejectCrossOrigin, time.Duration(0)
+	if sameOrigin(r) {
+		reason, wait = s.screenClick(ctx, r, id)
+	}
+	if reason != "" {
+		s.countRejection(ctx, reason)
+		switch reason {
+		case rejectDuplicate:
+			// A visitor clicking the same card again is not an error.
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"ok":true}`))
+		case rejectRateLimited:
+			w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+			http.Error(w, "too many clicks", http.StatusTooManyRequests)
+		default:
+			http.Error(w, "click not counted", http.StatusForbidden)
+		}
+		return
 	}
 
+	s.recordClick(r, id)
+
 	w.Header().Set("Content-Type", "application/json")
 	w.Write([]byte(`{"ok":true}`))
 }
 
-func (s *Server) renderTemplate(w http.ResponseWriter, name string, data any) error {
+// renderTemplate executes the named template with data. Templates can call
+// baseURL and absURL to build absolute URLs for r, and csrfField in admin
+// forms.
+func (s *Server) renderTemplate(w http.ResponseWriter, r *http.Request, name string, data any) error {
 	path := filepath.Join(s.TemplatesDir, name)
-	tmpl, err := template.ParseFiles(path)
+	base := s.baseURL(r)
+	tmpl, err := template.New(name).Funcs(template.FuncMap{
+		"baseURL":   func() string { return base },
+		"absURL":    func(path string) string { return absURL(base, path) },
+		"csrfField": func() template.HTML { return csrfField(r) },
+		"sdgIcon":   s.sdgIcon,
+	}).ParseFiles(path)
 	if err != nil {
 		return fmt.Errorf("parse template %q: %w", name, err)
 	}
@@ -333,10 +528,40 @@ func (s *Server) seedApps() error {
 		},
 	}
 
+	seedTags := map[string][]string{
+		"https://holzeinschlag-at.exe.xyz/":       {"Environment"},
+		"https://groundwater-at.exe.xyz/":         {"Environment", "Energy"},
+		"https://msf-prep.exe.xyz/":               {"Health"},
+		"https://schools-at.exe.xyz/":             {"Education"},
+		"https://maternity-ward-closure.exe.xyz/": {"Health"},
+		"https://child-care-access-at.exe.xyz/":   {"Education"},
+		"https://austria-power.exe.xyz/":          {"Energy"},
+		"https://farm-subsidies-austria.exe.xyz/": {"Agriculture"},
+	}
+
+	seedSDGs := map[string][]int64{
+		"https://holzeinschlag-at.exe.xyz/":       {13, 15},
+		"https://groundwater-at.exe.xyz/":         {6, 7},
+		"https://msf-prep.exe.xyz/":               {3},
+		"https://schools-at.exe.xyz/":             {4},
+		"https://maternity-ward-closure.exe.xyz/": {3},
+		"https://child-care-access-at.exe.xyz/":   {4, 5},
+		"https://austria-power.exe.xyz/":          {7, 13},
+		"https://farm-subsidies-austria.exe.xyz/": {2},
+	}
+
 	for _, app := range seedData {
-		_, err := q.CreateApp(ctx, app)
+		app.Slug = slugify(app.Title)
+		created, err := q.CreateApp(ctx, app)
 		if err != nil {
 			slog.Warn("seed app", "title", app.Title, "error", err)
+			continue
+		}
+		if err := s.setAppTags(ctx, created.ID, seedTags[app.Url]); err != nil {
+			slog.Warn("seed app tags", "title", app.Title, "error", err)
+		}
+		if err := s.setAppSDGs(ctx, created.ID, seedSDGs[app.Url]); err != nil {
+			slog.Warn("seed app sdgs", "title", app.Title, "error", err)
 		}
 	}
 	return nil
@@ -346,74 +571,56 @@ func ptr[T any](v T) *T {
 	return &v
 }
 
-func (s *Server) HandleSitemap(w http.ResponseWriter, r *http.Request) {
-	q := dbgen.New(s.DB)
-	apps, _ := q.ListApps(r.Context())
-
-	w.Header().Set("Content-Type", "application/xml")
-	w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
-<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
-  <url>
-    <loc>https://kohlschwarz.at:8000/</loc>
-    <changefreq>weekly</changefreq>
-    <priority>1.0</priority>
-  </url>
-  <url>
-    <loc>https://kohlschwarz.at:8000/impressum</loc>
-    <changefreq>monthly</changefreq>
-    <priority>0.3</priority>
-  </url>
-  <url>
-    <loc>https://kohlschwarz.at:8000/datenschutz</loc>
-    <changefreq>monthly</changefreq>
-    <priority>0.3</priority>
-  </url>
-`))
-	for _, app := range apps {
-		fmt.Fprintf(w, `  <url>
-    <loc>%s</loc>
-    <changefreq>monthly</changefreq>
-    <priority>0.8</priority>
-  </url>
-`, app.Url)
-	}
-	w.Write([]byte(`</urlset>`))
-}
-
 func (s *Server) HandleRobots(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/plain")
-	w.Write([]byte(`User-agent: *
+	fmt.Fprintf(w, `User-agent: *
 Allow: /
 Disallow: /admin
 
-Sitemap: https://kohlschwarz.at:8000/sitemap.xml
-`))
+Sitemap: %s/sitemap.xml
+`, s.baseURL(r))
 }
 
 func (s *Server) HandleImpressum(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	if err := s.renderTemplate(w, "impressum.html", nil); err != nil {
+	if err := s.renderTemplate(w, r, "impressum.html", nil); err != nil {
 		slog.Warn("render template", "url", r.URL.Path, "error", err)
 	}
 }
 
 func (s *Server) HandleDatenschutz(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	if err := s.renderTemplate(w, "datenschutz.html", nil); err != nil {
+	if err := s.renderTemplate(w, r, "datenschutz.html", nil); err != nil {
 		slog.Warn("render template", "url", r.URL.Path, "error", err)
 	}
 }
 
+// adminPath reports whether path belongs to the admin: its pages, the login
+// form and invite links, which log in too.
+func adminPath(path string) bool {
+	return path == "/admin" || strings.HasPrefix(path, "/admin/") || strings.HasPrefix(path, "/invite/")
+}
+
 func securityHeaders(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("X-Content-Type-Options", "nosniff")
-		w.Header().Set("X-Frame-Options", "DENY")
+		// Embed views exist to be framed by other sites; everything else
+		// may not be.
+		if r.URL.Path == "/embed" || strings.HasPrefix(r.URL.Path, "/embed/") {
+			w.Header().Set("Content-Security-Policy", "frame-ancestors *")
+		} else {
+			w.Header().Set("X-Frame-Options", "DENY")
+		}
 		w.Header().Set("Referrer-Policy", "strict-origin-when-cross-origin")
 		w.Header().Set("Permissions-Policy", "geolocation=(), microphone=(), camera=()")
-		// Cache static assets for 1 week, HTML for 1 hour
-		if strings.HasPrefix(r.URL.Path, "/static/") {
+		// Cache static assets for 1 week, public HTML for 1 hour. Admin
+		// pages show private data, invite links and CSRF tokens, so they
+		// are never stored.
+		if adminPath(r.URL.Path) {
+			w.Header().Set("Cache-Control", "no-store")
+		} else if strings.HasPrefix(r.URL.Path, "/static/") {
 			w.Header().Set("Cache-Control", "public, max-age=604800, immutable")
-		} else if r.URL.Path == "/sitemap.xml" || r.URL.Path == "/robots.txt" {
+		} else if r.URL.Path == "/sitemap.xml" || r.URL.Path == "/robots.txt" || strings.HasPrefix(r.URL.Path, "/sitemaps/") {
 			w.Header().Set("Cache-Control", "public, max-age=86400")
 		} else {
 			w.Header().Set("Cache-Control", "public, max-age=3600")
@@ -422,21 +629,93 @@ func securityHeaders(next http.Handler) http.Handler {
 	})
 }
 
-func (s *Server) Serve(addr string) error {
+// Handler routes requests to the server's handlers, wrapped in the
+// middleware every response goes through.
+func (s *Server) Handler() http.Handler {
 	mux := http.NewServeMux()
-	mux.HandleFunc("GET /{$}", s.HandleRoot)
+	mux.HandleFunc("GET /{$}", s.countVisitor(s.HandleRoot))
+	mux.HandleFunc("GET /tag/{slug}", s.HandleTag)
+	mux.HandleFunc("GET /sdg", s.HandleSDG)
+	mux.HandleFunc("GET /apps/{slug}", s.HandleApp)
+	mux.HandleFunc("GET /apps/{id}/qr.svg", s.HandleQRSVG)
+	mux.HandleFunc("GET /apps/{id}/qr.png", s.HandleQRPNG)
+	mux.HandleFunc("GET /apps/{id}/poster", s.HandlePoster)
+	mux.HandleFunc("GET /apps/{id}/cite.bib", s.HandleCiteBib)
+	mux.HandleFunc("GET /apps/{id}/cite.json", s.HandleCiteJSON)
+	mux.HandleFunc("GET /apps/{id}/cite.ris", s.HandleCiteRIS)
+	mux.HandleFunc("GET /cite.bib", s.HandleCiteAll)
 	mux.HandleFunc("GET /impressum", s.HandleImpressum)
 	mux.HandleFunc("GET /datenschutz", s.HandleDatenschutz)
 	mux.HandleFunc("GET /sitemap.xml", s.HandleSitemap)
+	mux.HandleFunc("GET /sitemaps/{name}", s.HandleSitemapPart)
 	mux.HandleFunc("GET /robots.txt", s.HandleRobots)
+	mux.HandleFunc("GET /feed.xml", s.HandleAtomFeed)
+	mux.HandleFunc("GET /rss.xml", s.HandleRSSFeed)
+	mux.HandleFunc("GET /feed.json", s.HandleJSONFeed)
+	mux.HandleFunc("GET /catalog.pdf", s.HandleCatalog)
+	mux.HandleFunc("GET /admin/login", s.HandleLoginPage)
+	mux.HandleFunc("POST /admin/login", s.HandleLogin)
+	mux.HandleFunc("POST /admin/logout", s.HandleLogout)
 	mux.HandleFunc("GET /admin", s.HandleAdmin)
+	mux.HandleFunc("GET /admin/stats", s.HandleAdminStats)
 	mux.HandleFunc("GET /admin/edit/{id}", s.HandleAdminEdit)
 	mux.HandleFunc("GET /admin/new", s.HandleAdminEdit)
 	mux.HandleFunc("POST /admin/save", s.HandleAdminSave)
 	mux.HandleFunc("POST /admin/delete/{id}", s.HandleAdminDelete)
+	mux.HandleFunc("POST /admin/ranking", s.HandleAdminRanking)
+	mux.HandleFunc("POST /admin/catalog", s.HandleAdminCatalog)
+	mux.HandleFunc("GET /admin/users", s.HandleAdminUsers)
+	mux.HandleFunc("POST /admin/users/{id}/role", s.HandleAdminUserRole)
+	mux.HandleFunc("POST /admin/users/{id}/delete", s.HandleAdminUserDelete)
+	mux.HandleFunc("POST /admin/invites", s.HandleAdminInvite)
+	mux.HandleFunc("POST /admin/invites/{id}/delete", s.HandleAdminInviteDelete)
+	mux.HandleFunc("POST /admin/lockouts/clear", s.HandleAdminLockoutClear)
+	mux.HandleFunc("GET /invite/{token}", s.HandleInvitePage)
+	mux.HandleFunc("POST /invite/{token}", s.HandleAcceptInvite)
 	mux.HandleFunc("GET /api/apps", s.HandleAPIApps)
 	mux.HandleFunc("POST /api/click/{id}", s.HandleTrackClick)
+	mux.HandleFunc("GET /go/{id}", s.HandleGo)
+	mux.HandleFunc("GET /embed", s.HandleEmbedList)
+	mux.HandleFunc("GET /embed/{id}", s.HandleEmbed)
+	mux.HandleFunc("GET /oembed", s.HandleOEmbed)
+	mux.HandleFunc("GET /widget.js", s.HandleWidgetJS)
+	mux.HandleFunc("GET /og/{file}", s.HandleOGImage)
 	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir(s.StaticDir))))
-	slog.Info("starting server", "addr", addr)
-	return http.ListenAndServe(addr, securityHeaders(mux))
+	return s.canonicalHost(securityHeaders(s.csrfProtect(mux)))
+}
+
+func (s *Server) Serve(addr string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go s.runClickRollups(ctx, clickRollupInterval)
+
+	// The flusher outlives the HTTP server so clicks from requests that
+	// are still draining during shutdown get written too.
+	flushCtx, stopFlush := context.WithCancel(context.Background())
+	flushed := make(chan struct{})
+	go func() {
+		s.runClickFlusher(flushCtx, s.ClickFlushInterval)
+		close(flushed)
+	}()
+	defer func() {
+		stopFlush()
+		<-flushed
+	}()
+
+	httpServer := &http.Server{Addr: addr, Handler: s.Handler()}
+	errc := make(chan error, 1)
+	go func() {
+		slog.Info("starting server", "addr", addr)
+		errc <- httpServer.ListenAndServe()
+	}()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return httpServer.Shutdown(shutdownCtx)
 }
@@ -0,0 +1,44 @@
+package srv
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"srv.exe.dev/db/dbgen"
+)
+
+func TestMain(m *testing.M) {
+	// Migrations and handlers log a lot; keep test output readable.
+	slog.SetDefault(slog.New(slog.DiscardHandler))
+	os.Exit(m.Run())
+}
+
+// newTestServer returns a Server on a fresh database in a temporary
+// directory, filled with the seed apps.
+func newTestServer(tb testing.TB) *Server {
+	tb.Helper()
+	dir := tb.TempDir()
+	s, err := New(filepath.Join(dir, "db.sqlite3"), "localhost")
+	if err != nil {
+		tb.Fatalf("New: %v", err)
+	}
+	s.CacheDir = filepath.Join(dir, "cache")
+	tb.Cleanup(func() { s.DB.Close() })
+	return s
+}
+
+// firstApp returns one of the seed apps.
+func firstApp(tb testing.TB, s *Server) dbgen.App {
+	tb.Helper()
+	apps, err := dbgen.New(s.DB).ListApps(context.Background())
+	if err != nil {
+		tb.Fatalf("ListApps: %v", err)
+	}
+	if len(apps) == 0 {
+		tb.Fatal("no seed apps")
+	}
+	return apps[0]
+}
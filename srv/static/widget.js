@@ -0,0 +1,44 @@
+// Kohlschwarz Think-Tank app widget.
+//
+// Paste where the cards should appear:
+//
+//   <script src="https://kohlschwarz.at:8000/widget.js" data-tag="health" data-limit="3" async></script>
+//
+// Options (all optional):
+//   data-tag    only apps with this tag (slug); several separated by commas
+//   data-match  "any" to show apps with any of the tags instead of all
+//   data-sdg    only apps linked to this SDG number
+//   data-limit  show at most this many apps (the top N on the homepage)
+//   data-app    show exactly one app, by id
+(function () {
+    var script = document.currentScript;
+    if (!script) return;
+    var base = new URL(script.src).origin;
+    var d = script.dataset;
+
+    var src;
+    if (d.app) {
+        src = base + '/embed/' + encodeURIComponent(d.app);
+    } else {
+        var params = new URLSearchParams();
+        if (d.tag) d.tag.split(',').forEach(function (t) { params.append('tag', t.trim()); });
+        if (d.match) params.set('match', d.match);
+        if (d.sdg) params.set('sdg', d.sdg);
+        if (d.limit) params.set('limit', d.limit);
+        src = base + '/embed?' + params.toString();
+    }
+
+    var frame = document.createElement('iframe');
+    frame.src = src;
+    frame.title = 'Kohlschwarz Think-Tank';
+    frame.loading = 'lazy';
+    frame.style.cssText = 'border:0;width:100%;height:440px;display:block';
+    script.parentNode.insertBefore(frame, script.nextSibling);
+
+    window.addEventListener('message', function (e) {
+        if (e.origin !== base || e.source !== frame.contentWindow) return;
+        if (e.data && e.data.kohlschwarzEmbed && e.data.height > 0) {
+            frame.style.height = e.data.height + 'px';
+        }
+    });
+})();
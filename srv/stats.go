@@ -0,0 +1,306 @@
+package srv
+
+import (
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"srv.exe.dev/db/dbgen"
+)
+
+const (
+	dayFormat         = "2006-01-02"
+	defaultStatsDays  = 30
+	maxStatsRangeDays = 366
+
+	// topReferrerHosts is how many referring sites the stats page lists.
+	topReferrerHosts = 10
+)
+
+type statsPageData struct {
+	Hostname      string
+	From          string
+	To            string
+	Days          int
+	TotalClicks   int64
+	TotalViews    int64
+	TotalVisitors int64
+	ClicksChart   template.HTML
+	ViewsChart    template.HTML
+	ReferrerChart template.HTML
+	HostChart     template.HTML
+	RejectChart   template.HTML
+	TotalRejected int64
+	EmbedChart    template.HTML
+	TotalEmbeds   int64
+	TotalScans    int64
+	Apps          []appStats
+}
+
+// appStats is one row of the per-app table on the stats page.
+type appStats struct {
+	ID         int64
+	Title      string
+	ClickCount int64
+	Clicks     int64
+	EmbedViews int64
+	Scans      int64
+	Sparkline  template.HTML
+	Trend7     trend
+	Trend30    trend
+}
+
+// trend compares the clicks of the most recent window with the window
+// before it.
+type trend struct {
+	Current  int64
+	Previous int64
+}
+
+// Change formats the relative change, e.g. "+25%".
+func (t trend) Change() string {
+	switch {
+	case t.Previous == 0 && t.Current == 0:
+		return "–"
+	case t.Previous == 0:
+		return "new"
+	}
+	pct := float64(t.Current-t.Previous) / float64(t.Previous) * 100
+	return fmt.Sprintf("%+.0f%%", pct)
+}
+
+// Up reports whether the current window beats the previous one.
+func (t trend) Up() bool {
+	return t.Current > t.Previous
+}
+
+// statsRange reads the date range from ?from=&to= or ?days=, defaulting to
+// the last defaultStatsDays days up to today (UTC).
+func statsRange(r *http.Request, now time.Time) (from, to time.Time) {
+	to = now.UTC().Truncate(24 * time.Hour)
+	if t, err := time.Parse(dayFormat, r.FormValue("to")); err == nil && t.Before(to) {
+		to = t
+	}
+	days := defaultStatsDays
+	if d, err := strconv.Atoi(r.FormValue("days")); err == nil && d > 0 {
+		days = d
+	}
+	from = to.AddDate(0, 0, -(days - 1))
+	if t, err := time.Parse(dayFormat, r.FormValue("from")); err == nil && !t.After(to) {
+		from = t
+	}
+	if to.Sub(from) > maxStatsRangeDays*24*time.Hour {
+		from = to.AddDate(0, 0, -(maxStatsRangeDays - 1))
+	}
+	return from, to
+}
+
+// dayLabels lists every day from..to inclusive.
+func dayLabels(from, to time.Time) []string {
+	var days []string
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		days = append(days, d.Format(dayFormat))
+	}
+	return days
+}
+
+// dailyByApp spreads rows into one series per app aligned to days.
+func dailyByApp(rows []dbgen.ListDailyClicksRow, days []string) map[int64][]int64 {
+	index := make(map[string]int, len(days))
+	for i, d := range days {
+		index[d] = i
+	}
+	series := make(map[int64][]int64)
+	for _, row := range rows {
+		i, ok := index[row.Day]
+		if !ok {
+			continue
+		}
+		if series[row.AppID] == nil {
+			series[row.AppID] = make([]int64, len(days))
+		}
+		series[row.AppID][i] += row.Clicks
+	}
+	return series
+}
+
+func sum(values []int64) int64 {
+	var n int64
+	for _, v := range values {
+		n += v
+	}
+	return n
+}
+
+// windowTrend compares the sum of the last n values with the n before.
+func windowTrend(values []int64, n int) trend {
+	if len(values) < 2*n {
+		return trend{}
+	}
+	end := len(values)
+	return trend{
+		Current:  sum(values[end-n:]),
+		Previous: sum(values[end-2*n : end-n]),
+	}
+}
+
+func (s *Server) HandleAdminStats(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.requireRole(w, r, roleViewer); !ok {
+		return
+	}
+
+	ctx := r.Context()
+	q := dbgen.New(s.DB)
+	now := time.Now()
+	from, to := statsRange(r, now)
+	days := dayLabels(from, to)
+	fromDay, toDay := from.Format(dayFormat), to.Format(dayFormat)
+
+	data := statsPageData{
+		Hostname: s.Hostname,
+		From:     fromDay,
+		To:       toDay,
+		Days:     len(days),
+	}
+
+	apps, err := q.ListApps(ctx)
+	if err != nil {
+		slog.Warn("list apps", "error", err)
+	}
+	clickRows, err := q.ListDailyClicks(ctx, dbgen.ListDailyClicksParams{FromDay: fromDay, ToDay: toDay})
+	if err != nil {
+		slog.Warn("daily clicks", "error", err)
+	}
+	perApp := dailyByApp(clickRows, days)
+
+	// Trends always look at the 60 days up to today, whatever the range.
+	today := now.UTC().Truncate(24 * time.Hour)
+	trendDays := dayLabels(today.AddDate(0, 0, -59), today)
+	trendRows, err := q.ListDailyClicks(ctx, dbgen.ListDailyClicksParams{FromDay: trendDays[0], ToDay: trendDays[len(trendDays)-1]})
+	if err != nil {
+		slog.Warn("trend clicks", "error", err)
+	}
+	trendByApp := dailyByApp(trendRows, trendDays)
+
+	embedRows, err := q.ListDailyEmbedViews(ctx, dbgen.ListDailyEmbedViewsParams{FromDay: fromDay, ToDay: toDay})
+	if err != nil {
+		slog.Warn("daily embed views", "error", err)
+	}
+	embedsByApp := make(map[int64]int64)
+	embedsByDay := make(map[string]int64)
+	for _, row := range embedRows {
+		embedsByApp[row.AppID] += row.Views
+		embedsByDay[row.Day] += row.Views
+		data.TotalEmbeds += row.Views
+	}
+	embeds := make([]int64, len(days))
+	for i, d := range days {
+		embeds[i] = embedsByDay[d]
+	}
+	data.EmbedChart = barChart(days, embeds, "#999")
+
+	scanRows, err := q.ListDailyQRScans(ctx, dbgen.ListDailyQRScansParams{FromDay: fromDay, ToDay: toDay})
+	if err != nil {
+		slog.Warn("daily qr scans", "error", err)
+	}
+	scansByApp := make(map[int64]int64)
+	for _, row := range scanRows {
+		scansByApp[row.AppID] += row.Scans
+		data.TotalScans += row.Scans
+	}
+
+	totals := make([]int64, len(days))
+	for _, app := range apps {
+		series := perApp[app.ID]
+		if series == nil {
+			series = make([]int64, len(days))
+		}
+		for i, v := range series {
+			totals[i] += v
+		}
+		var clickCount int64
+		if app.ClickCount != nil {
+			clickCount = *app.ClickCount
+		}
+		data.Apps = append(data.Apps, appStats{
+			ID:         app.ID,
+			Title:      app.Title,
+			ClickCount: clickCount,
+			Clicks:     sum(series),
+			EmbedViews: embedsByApp[app.ID],
+			Scans:      scansByApp[app.ID],
+			Sparkline:  sparkline(series, "#4a6fa5"),
+			Trend7:     windowTrend(trendByApp[app.ID], 7),
+			Trend30:    windowTrend(trendByApp[app.ID], 30),
+		})
+	}
+	data.TotalClicks = sum(totals)
+	data.ClicksChart = barChart(days, totals, "#4a6fa5")
+
+	visitorRows, err := q.ListDailyVisitors(ctx, dbgen.ListDailyVisitorsParams{FromDay: fromDay, ToDay: toDay})
+	if err != nil {
+		slog.Warn("daily visitors", "error", err)
+	}
+	views := make([]int64, len(days))
+	for _, row := range visitorRows {
+		for i, d := range days {
+			if d == row.Day {
+				views[i] = row.Views
+			}
+		}
+		data.TotalViews += row.Views
+		data.TotalVisitors += row.Visitors
+	}
+	data.ViewsChart = barChart(days, views, "#111")
+
+	refRows, err := q.ListReferrerClasses(ctx, dbgen.ListReferrerClassesParams{FromDay: fromDay, ToDay: toDay})
+	if err != nil {
+		slog.Warn("referrer classes", "error", err)
+	}
+	var refLabels []string
+	var refValues []int64
+	for _, row := range refRows {
+		refLabels = append(refLabels, row.ReferrerClass)
+		refValues = append(refValues, row.Clicks)
+	}
+	if len(refRows) > 0 {
+		data.ReferrerChart = hbarChart(refLabels, refValues, "#999")
+	}
+
+	hostRows, err := q.ListReferrerHosts(ctx, dbgen.ListReferrerHostsParams{FromDay: fromDay, ToDay: toDay, MaxHosts: topReferrerHosts})
+	if err != nil {
+		slog.Warn("referrer hosts", "error", err)
+	}
+	var hostLabels []string
+	var hostValues []int64
+	for _, row := range hostRows {
+		hostLabels = append(hostLabels, row.ReferrerHost)
+		hostValues = append(hostValues, row.Clicks)
+	}
+	if len(hostRows) > 0 {
+		data.HostChart = hbarChart(hostLabels, hostValues, "#999")
+	}
+
+	rejectRows, err := q.ListClickRejections(ctx, dbgen.ListClickRejectionsParams{FromDay: fromDay, ToDay: toDay})
+	if err != nil {
+		slog.Warn("click rejections", "error", err)
+	}
+	var rejectLabels []string
+	var rejectValues []int64
+	for _, row := range rejectRows {
+		rejectLabels = append(rejectLabels, row.Reason)
+		rejectValues = append(rejectValues, row.Count)
+		data.TotalRejected += row.Count
+	}
+	if len(rejectRows) > 0 {
+		data.RejectChart = hbarChart(rejectLabels, rejectValues, "#c33")
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.renderTemplate(w, r, "stats.html", data); err != nil {
+		slog.Warn("render template", "url", r.URL.Path, "error", err)
+	}
+}
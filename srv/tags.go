@@ -0,0 +1,167 @@
+package srv
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"srv.exe.dev/db/dbgen"
+)
+
+// tagFacet is a tag with the number of apps carrying it.
+type tagFacet struct {
+	Slug  string `json:"slug"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// hasTags reports whether app carries the tags in slugs: all of them, or
+// any of them if matchAny is set.
+func hasTags(app appEntry, slugs []string, matchAny bool) bool {
+	for _, slug := range slugs {
+		found := slices.ContainsFunc(app.Tags, func(t dbgen.Tag) bool { return t.Slug == slug })
+		if found && matchAny {
+			return true
+		}
+		if !found && !matchAny {
+			return false
+		}
+	}
+	return !matchAny
+}
+
+// facetCounts counts how many of apps carry each tag, most common first.
+func facetCounts(apps []appEntry) []tagFacet {
+	facets := []tagFacet{}
+	index := make(map[string]int)
+	for _, app := range apps {
+		for _, t := range app.Tags {
+			i, ok := index[t.Slug]
+			if !ok {
+				i = len(facets)
+				index[t.Slug] = i
+				facets = append(facets, tagFacet{Slug: t.Slug, Name: t.Name})
+			}
+			facets[i].Count++
+		}
+	}
+	slices.SortStableFunc(facets, func(a, b tagFacet) int {
+		if a.Count != b.Count {
+			return int(b.Count - a.Count)
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return facets
+}
+
+// allTagFacets returns every tag that is in use, with its app count, for
+// the filter chips on the index page.
+func (s *Server) allTagFacets(ctx context.Context) []tagFacet {
+	rows, err := dbgen.New(s.DB).ListTagCounts(ctx)
+	if err != nil {
+		slog.Warn("list tags", "error", err)
+		return nil
+	}
+	var facets []tagFacet
+	for _, row := range rows {
+		if row.Apps > 0 {
+			facets = append(facets, tagFacet{Slug: row.Slug, Name: row.Name, Count: row.Apps})
+		}
+	}
+	return facets
+}
+
+// tagParams reads ?tag= from r; both repeated parameters and
+// comma-separated lists are accepted.
+func tagParams(r *http.Request) []string {
+	var slugs []string
+	for _, v := range r.URL.Query()["tag"] {
+		for _, part := range strings.Split(v, ",") {
+			if slug := slugify(part); slug != "" && !slices.Contains(slugs, slug) {
+				slugs = append(slugs, slug)
+			}
+		}
+	}
+	return slugs
+}
+
+// parseTagNames splits the comma-separated tag field of the edit form.
+func parseTagNames(raw string) []string {
+	var names []string
+	for _, part := range strings.Split(raw, ",") {
+		if name := strings.TrimSpace(part); name != "" && slugify(name) != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// setAppTags replaces the tags of an app with names, creating tags as
+// needed, and drops tags no app uses any more.
+func (s *Server) setAppTags(ctx context.Context, appID int64, names []string) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := dbgen.New(tx)
+	if err := q.DeleteAppTags(ctx, appID); err != nil {
+		return err
+	}
+	for _, name := range names {
+		tag, err := q.UpsertTag(ctx, dbgen.UpsertTagParams{Slug: slugify(name), Name: name})
+		if err != nil {
+			return err
+		}
+		if err := q.AddAppTag(ctx, dbgen.AddAppTagParams{AppID: appID, TagID: tag.ID}); err != nil {
+			return err
+		}
+	}
+	if err := q.DeleteUnusedTags(ctx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// HandleTag shows the index page filtered to one tag.
+func (s *Server) HandleTag(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tag, err := dbgen.New(s.DB).GetTagBySlug(ctx, r.PathValue("slug"))
+	if errors.Is(err, sql.ErrNoRows) {
+		s.notFound(w, r)
+		return
+	}
+	if err != nil {
+		slog.Warn("get tag", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	apps, err := s.listApps(ctx, appQuery{
+		Ranking: s.activeRanking(ctx),
+		Tags:    []string{tag.Slug},
+	})
+	if err != nil {
+		slog.Warn("list apps", "error", err)
+	}
+
+	data := pageData{
+		Hostname: s.Hostname,
+		Apps:     apps,
+		Tags:     s.allTagFacets(ctx),
+		Tag:      &tag,
+		Goals:    s.sdgChoices(ctx, 0),
+		About:    sdgTerms(sdgsInUse(apps)),
+		AppList:  appListJSONLD(s.baseURL(r), apps),
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.renderTemplate(w, r, "index.html", data); err != nil {
+		slog.Warn("render template", "url", r.URL.Path, "error", err)
+	}
+}
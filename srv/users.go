@@ -0,0 +1,365 @@
+package srv
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"srv.exe.dev/db/dbgen"
+)
+
+// role is what an admin may do. Each role includes the ones before it.
+type role string
+
+const (
+	roleViewer role = "viewer" // sees the admin list and stats
+	roleEditor role = "editor" // creates and edits apps, orders the homepage
+	roleOwner  role = "owner"  // deletes apps, changes settings, manages users
+)
+
+// roles lists every role from least to most privileged.
+var roles = []role{roleViewer, roleEditor, roleOwner}
+
+// atLeast reports whether rl includes need. Unknown roles include nothing.
+func (rl role) atLeast(need role) bool {
+	have := slices.Index(roles, rl)
+	return have >= 0 && have >= slices.Index(roles, need)
+}
+
+// CanEdit, CanDelete and CanManage tell templates which actions to offer.
+func (rl role) CanEdit() bool   { return rl.atLeast(roleEditor) }
+func (rl role) CanDelete() bool { return rl.atLeast(roleOwner) }
+func (rl role) CanManage() bool { return rl.atLeast(roleOwner) }
+
+func parseRole(s string) (role, error) {
+	if !slices.Contains(roles, role(s)) {
+		return "", fmt.Errorf("unknown role %q", s)
+	}
+	return role(s), nil
+}
+
+const (
+	// defaultInviteDays is how long an invite link is valid unless the
+	// owner picks otherwise; maxInviteDays caps the choice.
+	defaultInviteDays = 7
+	maxInviteDays     = 30
+)
+
+type usersPageData struct {
+	User      admin
+	Users     []dbgen.AdminUser
+	Invites   []dbgen.ListOpenInvitesRow
+	Roles     []role
+	InviteURL string // link of the invite just created, shown only once
+	Error     string
+
+	// Lockouts are the login throttles currently locked out, Failures the
+	// latest failed logins.
+	Lockouts []dbgen.LoginThrottle
+	Failures []dbgen.LoginFailure
+}
+
+// HandleAdminUsers lists admin accounts and open invites.
+func (s *Server) HandleAdminUsers(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.requireRole(w, r, roleOwner)
+	if !ok {
+		return
+	}
+	s.renderUsers(w, r, usersPageData{User: user})
+}
+
+func (s *Server) renderUsers(w http.ResponseWriter, r *http.Request, data usersPageData) {
+	ctx := r.Context()
+	q := dbgen.New(s.DB)
+	var err error
+	if data.Users, err = q.ListAdminUsers(ctx); err != nil {
+		slog.Warn("list admin users", "error", err)
+	}
+	if data.Invites, err = q.ListOpenInvites(ctx, time.Now().UTC()); err != nil {
+		slog.Warn("list invites", "error", err)
+	}
+	if data.Lockouts, err = q.ListLoginLockouts(ctx, time.Now().UTC()); err != nil {
+		slog.Warn("list login lockouts", "error", err)
+	}
+	if data.Failures, err = q.ListLoginFailures(ctx, loginAuditShown); err != nil {
+		slog.Warn("list login failures", "error", err)
+	}
+	data.Roles = roles
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.renderTemplate(w, r, "users.html", data); err != nil {
+		slog.Warn("render template", "url", r.URL.Path, "error", err)
+	}
+}
+
+// otherUserParam reads the {id} of a user to change. Owners can't change
+// or delete their own account, so they can't lock themselves out.
+func (s *Server) otherUserParam(w http.ResponseWriter, r *http.Request, self admin) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		http.Error(w, "bad user id", http.StatusBadRequest)
+		return 0, false
+	}
+	if id == self.ID {
+		http.Error(w, "you can't change your own account", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+// errLastOwner refuses a change that would leave no owner account.
+var errLastOwner = errors.New("the last owner can't be demoted or removed")
+
+// changeUser runs change on admin id in a transaction, unless id is the
+// only owner and change would take that away (keepsOwner false). Counting
+// and changing in one transaction means two owners demoting each other at
+// the same time can't both succeed.
+func (s *Server) changeUser(ctx context.Context, id int64, keepsOwner bool, change func(q *dbgen.Queries) error) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	q := dbgen.New(tx)
+	if !keepsOwner {
+		target, err := q.GetAdminUser(ctx, id)
+		if err != nil {
+			return err
+		}
+		if role(target.Role) == roleOwner {
+			owners, err := q.CountAdminOwners(ctx)
+			if err != nil {
+				return err
+			}
+			if owners <= 1 {
+				return errLastOwner
+			}
+		}
+	}
+	if err := change(q); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// userChangeError answers a failed changeUser.
+func userChangeError(w http.ResponseWriter, id int64, err error) {
+	switch {
+	case errors.Is(err, errLastOwner):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, sql.ErrNoRows):
+		http.Error(w, "no such user", http.StatusNotFound)
+	default:
+		slog.Warn("change admin user", "id", id, "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
+
+// HandleAdminUserRole changes another admin's role.
+func (s *Server) HandleAdminUserRole(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.requireRole(w, r, roleOwner)
+	if !ok {
+		return
+	}
+	id, ok := s.otherUserParam(w, r, user)
+	if !ok {
+		return
+	}
+	rl, err := parseRole(r.FormValue("role"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = s.changeUser(r.Context(), id, rl == roleOwner, func(q *dbgen.Queries) error {
+		return q.SetAdminUserRole(r.Context(), dbgen.SetAdminUserRoleParams{ID: id, Role: string(rl)})
+	})
+	if err != nil {
+		userChangeError(w, id, err)
+		return
+	}
+	slog.Info("admin role changed", "by", user.Username, "id", id, "role", rl)
+	http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
+}
+
+// HandleAdminUserDelete removes another admin; their sessions go with them.
+func (s *Server) HandleAdminUserDelete(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.requireRole(w, r, roleOwner)
+	if !ok {
+		return
+	}
+	id, ok := s.otherUserParam(w, r, user)
+	if !ok {
+		return
+	}
+	err := s.changeUser(r.Context(), id, false, func(q *dbgen.Queries) error {
+		return q.DeleteAdminUser(r.Context(), id)
+	})
+	if err != nil {
+		userChangeError(w, id, err)
+		return
+	}
+	slog.Info("admin user deleted", "by", user.Username, "id", id)
+	http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
+}
+
+// HandleAdminInvite creates an invite link for a new admin with the chosen
+// role. Only its hash is stored, so the link is shown just this once.
+func (s *Server) HandleAdminInvite(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.requireRole(w, r, roleOwner)
+	if !ok {
+		return
+	}
+	rl, err := parseRole(r.FormValue("role"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	days, err := strconv.Atoi(r.FormValue("days"))
+	if err != nil || days < 1 || days > maxInviteDays {
+		days = defaultInviteDays
+	}
+
+	var createdBy *int64
+	if !user.ExeDev {
+		createdBy = &user.ID
+	}
+	token := rand.Text()
+	now := time.Now().UTC().Truncate(time.Second)
+	err = dbgen.New(s.DB).CreateInvite(r.Context(), dbgen.CreateInviteParams{
+		ID:        sessionID(token),
+		Role:      string(rl),
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		ExpiresAt: now.AddDate(0, 0, days),
+	})
+	if err != nil {
+		slog.Warn("create invite", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	slog.Info("admin invite created", "by", user.Username, "role", rl, "days", days)
+	s.renderUsers(w, r, usersPageData{User: user, InviteURL: s.baseURL(r) + "/invite/" + token})
+}
+
+// HandleAdminInviteDelete revokes an open invite.
+func (s *Server) HandleAdminInviteDelete(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.requireRole(w, r, roleOwner); !ok {
+		return
+	}
+	if err := dbgen.New(s.DB).DeleteInvite(r.Context(), r.PathValue("id")); err != nil {
+		slog.Warn("delete invite", "error", err)
+	}
+	http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
+}
+
+type invitePageData struct {
+	Role     role
+	Username string
+	Error    string
+	Invalid  bool
+}
+
+// openInvite loads the unused, unexpired invite for the {token} in r's path.
+func (s *Server) openInvite(r *http.Request) (dbgen.Invite, error) {
+	return dbgen.New(s.DB).GetOpenInvite(r.Context(), dbgen.GetOpenInviteParams{
+		ID:  sessionID(r.PathValue("token")),
+		Now: time.Now().UTC(),
+	})
+}
+
+func (s *Server) renderInvite(w http.ResponseWriter, r *http.Request, status int, data invitePageData) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := s.renderTemplate(w, r, "invite.html", data); err != nil {
+		slog.Warn("render template", "url", r.URL.Path, "error", err)
+	}
+}
+
+// HandleInvitePage shows the sign-up form of an invite link.
+func (s *Server) HandleInvitePage(w http.ResponseWriter, r *http.Request) {
+	inv, err := s.openInvite(r)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			slog.Warn("get invite", "error", err)
+		}
+		s.renderInvite(w, r, http.StatusNotFound, invitePageData{Invalid: true})
+		return
+	}
+	s.renderInvite(w, r, http.StatusOK, invitePageData{Role: role(inv.Role)})
+}
+
+// HandleAcceptInvite creates the invited account, uses up the invite and
+// logs the new admin in.
+func (s *Server) HandleAcceptInvite(w http.ResponseWriter, r *http.Request) {
+	inv, err := s.openInvite(r)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			slog.Warn("get invite", "error", err)
+		}
+		s.renderInvite(w, r, http.StatusNotFound, invitePageData{Invalid: true})
+		return
+	}
+
+	username := strings.TrimSpace(r.FormValue("username"))
+	password := r.FormValue("password")
+	data := invitePageData{Role: role(inv.Role), Username: username}
+	if err := checkPassword(password); err != nil {
+		data.Error = err.Error()
+	} else if password != r.FormValue("password2") {
+		data.Error = "The passwords don't match."
+	}
+	if data.Error != "" {
+		s.renderInvite(w, r, http.StatusBadRequest, data)
+		return
+	}
+
+	user, err := s.acceptInvite(r, inv, username, password)
+	if err != nil {
+		slog.Info("accept invite", "username", username, "error", err)
+		data.Error = "This username can't be used. Please pick another one."
+		s.renderInvite(w, r, http.StatusBadRequest, data)
+		return
+	}
+	slog.Info("admin user joined", "username", user.Username, "role", user.Role)
+	if err := s.startSession(w, r, user); err != nil {
+		slog.Warn("start session", "error", err)
+		http.Redirect(w, r, "/admin/login", http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/admin", http.StatusSeeOther)
+}
+
+// acceptInvite creates the user and marks inv used in one transaction, so
+// an invite can't be redeemed twice.
+func (s *Server) acceptInvite(r *http.Request, inv dbgen.Invite, username, password string) (dbgen.AdminUser, error) {
+	ctx := r.Context()
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return dbgen.AdminUser{}, err
+	}
+	defer tx.Rollback()
+
+	q := dbgen.New(tx)
+	user, err := createAdminUser(ctx, q, username, password, role(inv.Role))
+	if err != nil {
+		return dbgen.AdminUser{}, err
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	n, err := q.UseInvite(ctx, dbgen.UseInviteParams{ID: inv.ID, UsedAt: &now, UsedBy: &user.ID, Now: now})
+	if err != nil {
+		return dbgen.AdminUser{}, err
+	}
+	if n != 1 {
+		return dbgen.AdminUser{}, errors.New("invite already used or expired")
+	}
+	return user, tx.Commit()
+}
@@ -0,0 +1,131 @@
+package srv
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"srv.exe.dev/db/dbgen"
+)
+
+// dailySalt holds the salt for the current UTC day. Visitor ids are
+// sha256(salt, host, ip, user agent), so once a day's salt is gone the
+// ids recorded that day can no longer be tied to an IP address.
+type dailySalt struct {
+	mu   sync.Mutex
+	day  string
+	salt []byte
+}
+
+// visitorSalt returns the salt for day, creating it if needed and deleting
+// the salts of all earlier days.
+func (s *Server) visitorSalt(ctx context.Context, day string) ([]byte, error) {
+	s.salt.mu.Lock()
+	defer s.salt.mu.Unlock()
+	if s.salt.day == day {
+		return s.salt.salt, nil
+	}
+
+	q := dbgen.New(s.DB)
+	salt, err := q.GetVisitorSalt(ctx, day)
+	if errors.Is(err, sql.ErrNoRows) {
+		fresh := make([]byte, 32)
+		if _, err := rand.Read(fresh); err != nil {
+			return nil, fmt.Errorf("generate salt: %w", err)
+		}
+		if err := q.InsertVisitorSalt(ctx, dbgen.InsertVisitorSaltParams{Day: day, Salt: fresh}); err != nil {
+			return nil, fmt.Errorf("store salt: %w", err)
+		}
+		salt, err = q.GetVisitorSalt(ctx, day)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load salt: %w", err)
+	}
+	if err := q.DeleteVisitorSaltsBefore(ctx, day); err != nil {
+		return nil, fmt.Errorf("drop old salts: %w", err)
+	}
+	s.salt.day = day
+	s.salt.salt = salt
+	return salt, nil
+}
+
+// visitorID returns today's anonymous id for the client making r. Neither
+// the IP address nor the id itself ever leaves the server in a cookie.
+func (s *Server) visitorID(ctx context.Context, r *http.Request, now time.Time) (string, error) {
+	salt, err := s.visitorSalt(ctx, now.UTC().Format("2006-01-02"))
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	h.Write(salt)
+	for _, part := range []string{hostOnly(r.Host), s.clientIP(r), r.UserAgent()} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16]), nil
+}
+
+// countVisitor records a page view for the anonymous visitor before
+// handing the request on. Crawlers are not counted.
+func (s *Server) countVisitor(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && deviceClass(r.UserAgent()) != "bot" {
+			now := time.Now().UTC()
+			id, err := s.visitorID(r.Context(), r, now)
+			if err == nil {
+				err = dbgen.New(s.DB).UpsertVisitor(r.Context(), dbgen.UpsertVisitorParams{
+					ID:        id,
+					CreatedAt: now,
+					LastSeen:  now,
+				})
+			}
+			if err != nil {
+				slog.Warn("count visitor", "error", err)
+			}
+		}
+		next(w, r)
+	}
+}
+
+// clientIP returns the address of the client. Behind the exe.dev proxy the
+// peer is the proxy itself, so on connections from one of the trusted
+// proxies the last X-Forwarded-For hop (the one the proxy appended) is
+// used. Other clients can send any X-Forwarded-For they like, so for them
+// only RemoteAddr counts.
+func (s *Server) clientIP(r *http.Request) string {
+	if s.ExeDev.fromTrustedProxy(r) {
+		if ip := lastForwardedFor(r); ip != "" {
+			return ip
+		}
+	}
+	return remoteHost(r)
+}
+
+// lastForwardedFor returns the last hop of r's X-Forwarded-For header.
+func lastForwardedFor(r *http.Request) string {
+	xff := r.Header.Get("X-Forwarded-For")
+	if xff == "" {
+		return ""
+	}
+	hops := strings.Split(xff, ",")
+	return strings.TrimSpace(hops[len(hops)-1])
+}
+
+// remoteHost is the address of r's TCP peer.
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}